@@ -0,0 +1,281 @@
+package elasticorm
+
+import (
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// Aggregation describes an aggregation on a struct field. It is built with TermsAggregation, DateHistogramAggregation,
+// RangeAggregation, StatsAggregation, CardinalityAggregation or NestedAggregation and executed with Datastore.Aggregate
+type Aggregation struct {
+	kind     string
+	field    string
+	size     int
+	interval string
+	ranges   []AggregationRange
+	subs     map[string]Aggregation
+}
+
+// AggregationRange is one range of a RangeAggregation. A nil bound is open
+type AggregationRange struct {
+	Key  string
+	From interface{}
+	To   interface{}
+}
+
+const (
+	aggregationKindTerms         = `terms`
+	aggregationKindDateHistogram = `date_histogram`
+	aggregationKindRange         = `range`
+	aggregationKindStats         = `stats`
+	aggregationKindCardinality   = `cardinality`
+	aggregationKindNested        = `nested`
+)
+
+// TermsAggregation returns an aggregation with a bucket per value of the field. size limits the number of buckets, 0 uses the elasticsearch default
+func TermsAggregation(fieldName string, size int) Aggregation {
+	return Aggregation{kind: aggregationKindTerms, field: fieldName, size: size}
+}
+
+// DateHistogramAggregation returns an aggregation with a bucket per interval (e.g. day, week, month) of the date field
+func DateHistogramAggregation(fieldName string, interval string) Aggregation {
+	return Aggregation{kind: aggregationKindDateHistogram, field: fieldName, interval: interval}
+}
+
+// RangeAggregation returns an aggregation with a bucket per range of the field
+func RangeAggregation(fieldName string, ranges ...AggregationRange) Aggregation {
+	return Aggregation{kind: aggregationKindRange, field: fieldName, ranges: ranges}
+}
+
+// StatsAggregation returns an aggregation with count, min, max, avg and sum of the field
+func StatsAggregation(fieldName string) Aggregation {
+	return Aggregation{kind: aggregationKindStats, field: fieldName}
+}
+
+// CardinalityAggregation returns an aggregation with the approximate count of distinct values of the field
+func CardinalityAggregation(fieldName string) Aggregation {
+	return Aggregation{kind: aggregationKindCardinality, field: fieldName}
+}
+
+// NestedAggregation returns an aggregation on the nested documents of the field. The sub aggregations refer to the fields of the nested documents by their full path
+func NestedAggregation(fieldName string) Aggregation {
+	return Aggregation{kind: aggregationKindNested, field: fieldName}
+}
+
+// SubAggregation returns a copy of the aggregation, which aggregates the documents of each bucket with sub as well.
+// Only the bucket aggregations and NestedAggregation can have sub aggregations
+func (a Aggregation) SubAggregation(name string, sub Aggregation) Aggregation {
+	subs := make(map[string]Aggregation, len(a.subs)+1)
+	for n, s := range a.subs {
+		subs[n] = s
+	}
+	subs[name] = sub
+	a.subs = subs
+	return a
+}
+
+// AggregationResults maps the names of the aggregations to their results
+type AggregationResults map[string]AggregationResult
+
+// AggregationResult is the result of one aggregation. Depending on the kind of aggregation only some of the fields are filled
+type AggregationResult struct {
+	Buckets      []Bucket           // terms, date_histogram and range
+	Stats        *Stats             // stats
+	Value        *float64           // cardinality
+	DocCount     int64              // nested
+	Aggregations AggregationResults // sub aggregations of nested
+}
+
+// Bucket is a bucket of a terms, date_histogram or range aggregation
+type Bucket struct {
+	Key          interface{}
+	KeyAsString  string
+	From         *float64 // range only
+	To           *float64 // range only
+	DocCount     int64
+	Aggregations AggregationResults
+}
+
+// Stats is the result of a stats aggregation
+type Stats struct {
+	Count int64
+	Min   *float64
+	Max   *float64
+	Avg   *float64
+	Sum   *float64
+}
+
+// Aggregate executes the aggregations on all documents matching the query
+func (ds *Datastore) Aggregate(query Query, aggs map[string]Aggregation, opts ...QueryOptFunc) (AggregationResults, error) {
+	q, err := query.elasticQuery(ds.resolveFieldName)
+	if err != nil {
+		return nil, err
+	}
+	// the caller's slice must not be written to
+	opts = append(append([]QueryOptFunc{}, opts...), ds.Limit(0), ds.withAggregations(aggs))
+	res, _, err := ds.executeSearch(q, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAggregations(aggs, res.Aggregations)
+}
+
+// withAggregations adds the aggregations to the search
+func (ds *Datastore) withAggregations(aggs map[string]Aggregation) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		for name, agg := range aggs {
+			eagg, err := agg.elasticAggregation(ds.resolveFieldName)
+			if err != nil {
+				return errors.Wrapf(err, `aggregation %s`, name)
+			}
+			srv.Aggregation(name, eagg)
+		}
+		return nil
+	}
+}
+
+func (a Aggregation) elasticAggregation(resolve fieldNameResolver) (elastic.Aggregation, error) {
+	fieldName, err := resolve(a.field)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.subs) > 0 && (a.kind == aggregationKindStats || a.kind == aggregationKindCardinality) {
+		return nil, errors.Wrapf(ErrInvalidOption, "a %s aggregation can't have sub aggregations", a.kind)
+	}
+	subs := make(map[string]elastic.Aggregation, len(a.subs))
+	for name, sub := range a.subs {
+		subs[name], err = sub.elasticAggregation(resolve)
+		if err != nil {
+			return nil, errors.Wrapf(err, `sub aggregation %s`, name)
+		}
+	}
+
+	switch a.kind {
+	case aggregationKindTerms:
+		agg := elastic.NewTermsAggregation().Field(fieldName)
+		if a.size > 0 {
+			agg = agg.Size(a.size)
+		}
+		for name, sub := range subs {
+			agg = agg.SubAggregation(name, sub)
+		}
+		return agg, nil
+	case aggregationKindDateHistogram:
+		agg := elastic.NewDateHistogramAggregation().Field(fieldName).Interval(a.interval)
+		for name, sub := range subs {
+			agg = agg.SubAggregation(name, sub)
+		}
+		return agg, nil
+	case aggregationKindRange:
+		agg := elastic.NewRangeAggregation().Field(fieldName)
+		for _, r := range a.ranges {
+			if r.Key != `` {
+				agg = agg.AddRangeWithKey(r.Key, r.From, r.To)
+			} else {
+				agg = agg.AddRange(r.From, r.To)
+			}
+		}
+		for name, sub := range subs {
+			agg = agg.SubAggregation(name, sub)
+		}
+		return agg, nil
+	case aggregationKindStats:
+		return elastic.NewStatsAggregation().Field(fieldName), nil
+	case aggregationKindCardinality:
+		return elastic.NewCardinalityAggregation().Field(fieldName), nil
+	case aggregationKindNested:
+		agg := elastic.NewNestedAggregation().Path(fieldName)
+		for name, sub := range subs {
+			agg = agg.SubAggregation(name, sub)
+		}
+		return agg, nil
+	}
+	return nil, errors.Errorf("unknown aggregation kind %s", a.kind)
+}
+
+func decodeAggregations(aggs map[string]Aggregation, res elastic.Aggregations) (AggregationResults, error) {
+	if len(aggs) == 0 {
+		return nil, nil
+	}
+	results := make(AggregationResults, len(aggs))
+	for name, agg := range aggs {
+		result, err := agg.decode(name, res)
+		if err != nil {
+			return nil, err
+		}
+		results[name] = result
+	}
+	return results, nil
+}
+
+func (a Aggregation) decode(name string, res elastic.Aggregations) (AggregationResult, error) {
+	result := AggregationResult{}
+	var err error
+	missing := errors.Errorf("aggregation %s is missing in the response", name)
+	switch a.kind {
+	case aggregationKindTerms:
+		items, ok := res.Terms(name)
+		if !ok {
+			return result, missing
+		}
+		for _, item := range items.Buckets {
+			b := Bucket{Key: item.Key, DocCount: item.DocCount}
+			if item.KeyAsString != nil {
+				b.KeyAsString = *item.KeyAsString
+			}
+			if b.Aggregations, err = decodeAggregations(a.subs, item.Aggregations); err != nil {
+				return result, err
+			}
+			result.Buckets = append(result.Buckets, b)
+		}
+	case aggregationKindDateHistogram:
+		items, ok := res.DateHistogram(name)
+		if !ok {
+			return result, missing
+		}
+		for _, item := range items.Buckets {
+			b := Bucket{Key: item.Key, DocCount: item.DocCount}
+			if item.KeyAsString != nil {
+				b.KeyAsString = *item.KeyAsString
+			}
+			if b.Aggregations, err = decodeAggregations(a.subs, item.Aggregations); err != nil {
+				return result, err
+			}
+			result.Buckets = append(result.Buckets, b)
+		}
+	case aggregationKindRange:
+		items, ok := res.Range(name)
+		if !ok {
+			return result, missing
+		}
+		for _, item := range items.Buckets {
+			b := Bucket{Key: item.Key, KeyAsString: item.Key, From: item.From, To: item.To, DocCount: item.DocCount}
+			if b.Aggregations, err = decodeAggregations(a.subs, item.Aggregations); err != nil {
+				return result, err
+			}
+			result.Buckets = append(result.Buckets, b)
+		}
+	case aggregationKindStats:
+		stats, ok := res.Stats(name)
+		if !ok {
+			return result, missing
+		}
+		result.Stats = &Stats{Count: stats.Count, Min: stats.Min, Max: stats.Max, Avg: stats.Avg, Sum: stats.Sum}
+	case aggregationKindCardinality:
+		value, ok := res.Cardinality(name)
+		if !ok {
+			return result, missing
+		}
+		result.Value = value.Value
+	case aggregationKindNested:
+		nested, ok := res.Nested(name)
+		if !ok {
+			return result, missing
+		}
+		result.DocCount = nested.DocCount
+		if result.Aggregations, err = decodeAggregations(a.subs, nested.Aggregations); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
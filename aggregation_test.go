@@ -0,0 +1,153 @@
+package elasticorm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestAggregate(t *testing.T) {
+	type Order struct {
+		ID        string    `json:"id" elasticorm:"id"`
+		Status    string    `json:"status" elasticorm:"type=keyword"`
+		Customer  string    `json:"customer" elasticorm:"type=keyword"`
+		Amount    float64   `json:"amount"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	_, ds := initDatastore(t, &Order{})
+
+	day := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+	orders := []*Order{
+		&Order{Status: `open`, Customer: `a`, Amount: 10, CreatedAt: day},
+		&Order{Status: `open`, Customer: `b`, Amount: 20, CreatedAt: day},
+		&Order{Status: `closed`, Customer: `a`, Amount: 30, CreatedAt: day.AddDate(0, 0, 1)},
+	}
+	res, err := ds.BulkCreate(orders)
+	ok(t, err)
+	assert(t, !res.HasErrors(), `BulkCreate should not fail: %#v`, res.Failed)
+	ds.Refresh()
+
+	aggs, err := ds.Aggregate(elasticorm.Query{}, map[string]elasticorm.Aggregation{
+		`per_status`: elasticorm.TermsAggregation(`Status`, 10).
+			SubAggregation(`amount`, elasticorm.StatsAggregation(`Amount`)),
+		`per_day`:   elasticorm.DateHistogramAggregation(`CreatedAt`, `day`),
+		`customers`: elasticorm.CardinalityAggregation(`Customer`),
+		`amounts`: elasticorm.RangeAggregation(`Amount`,
+			elasticorm.AggregationRange{Key: `small`, To: 15},
+			elasticorm.AggregationRange{Key: `large`, From: 15},
+		),
+	})
+	ok(t, err)
+
+	perStatus := aggs[`per_status`].Buckets
+	equals(t, 2, len(perStatus))
+	equals(t, `open`, perStatus[0].Key)
+	equals(t, int64(2), perStatus[0].DocCount)
+	equals(t, 30.0, *perStatus[0].Aggregations[`amount`].Stats.Sum)
+	equals(t, `closed`, perStatus[1].Key)
+	equals(t, int64(1), perStatus[1].DocCount)
+
+	perDay := aggs[`per_day`].Buckets
+	equals(t, 2, len(perDay))
+	equals(t, int64(2), perDay[0].DocCount)
+	equals(t, int64(1), perDay[1].DocCount)
+
+	equals(t, 2.0, *aggs[`customers`].Value)
+
+	amounts := aggs[`amounts`].Buckets
+	equals(t, 2, len(amounts))
+	equals(t, `small`, amounts[0].Key)
+	equals(t, int64(1), amounts[0].DocCount)
+	equals(t, `large`, amounts[1].Key)
+	equals(t, int64(2), amounts[1].DocCount)
+}
+
+func TestNestedAggregation(t *testing.T) {
+	type Item struct {
+		Product  string `json:"product" elasticorm:"type=keyword"`
+		Quantity int    `json:"quantity"`
+	}
+	type Order struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Items []Item `json:"items"`
+	}
+	_, ds := initDatastore(t, &Order{})
+
+	orders := []*Order{
+		&Order{Items: []Item{{Product: `coffee`, Quantity: 2}, {Product: `tea`, Quantity: 1}}},
+		&Order{Items: []Item{{Product: `coffee`, Quantity: 3}}},
+	}
+	res, err := ds.BulkCreate(orders)
+	ok(t, err)
+	assert(t, !res.HasErrors(), `BulkCreate should not fail: %#v`, res.Failed)
+	ds.Refresh()
+
+	aggs, err := ds.Aggregate(elasticorm.Query{}, map[string]elasticorm.Aggregation{
+		`items`: elasticorm.NestedAggregation(`Items`).
+			SubAggregation(`per_product`, elasticorm.TermsAggregation(`Items.Product`, 10).
+				SubAggregation(`quantity`, elasticorm.StatsAggregation(`Items.Quantity`))),
+	})
+	ok(t, err)
+
+	items := aggs[`items`]
+	equals(t, int64(3), items.DocCount)
+	perProduct := items.Aggregations[`per_product`].Buckets
+	equals(t, 2, len(perProduct))
+	equals(t, `coffee`, perProduct[0].Key)
+	equals(t, int64(2), perProduct[0].DocCount)
+	equals(t, 5.0, *perProduct[0].Aggregations[`quantity`].Stats.Sum)
+	equals(t, `tea`, perProduct[1].Key)
+	equals(t, int64(1), perProduct[1].DocCount)
+}
+
+func TestAggregateDoesNotModifyOptions(t *testing.T) {
+	type Order struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Status string `json:"status" elasticorm:"type=keyword"`
+	}
+	_, ds := initDatastore(t, &Order{})
+
+	opts := make([]elasticorm.QueryOptFunc, 1, 3)
+	opts[0] = ds.FilterByField(`Status`, `open`)
+	_, err := ds.Aggregate(elasticorm.Query{}, map[string]elasticorm.Aggregation{
+		`per_status`: elasticorm.TermsAggregation(`Status`, 10),
+	}, opts...)
+	ok(t, err)
+	// the spare capacity of the passed slice must not have been written to
+	spare := opts[1:3]
+	assert(t, spare[0] == nil && spare[1] == nil, `Aggregate should not append to the passed options`)
+}
+
+func TestAggregateUnknownField(t *testing.T) {
+	type Order struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Status string `json:"status" elasticorm:"type=keyword"`
+	}
+	_, ds := initDatastore(t, &Order{})
+
+	_, err := ds.Aggregate(elasticorm.Query{}, map[string]elasticorm.Aggregation{
+		`per_state`: elasticorm.TermsAggregation(`State`, 10),
+	})
+	equals(t, `aggregation per_state: Mapping configuration has no mapping for struct field`, err.Error())
+}
+
+func TestAggregateMetricWithSubAggregation(t *testing.T) {
+	type Order struct {
+		ID       string  `json:"id" elasticorm:"id"`
+		Customer string  `json:"customer" elasticorm:"type=keyword"`
+		Amount   float64 `json:"amount"`
+	}
+	_, ds := initDatastore(t, &Order{})
+
+	for _, agg := range []elasticorm.Aggregation{
+		elasticorm.StatsAggregation(`Amount`),
+		elasticorm.CardinalityAggregation(`Customer`),
+	} {
+		_, err := ds.Aggregate(elasticorm.Query{}, map[string]elasticorm.Aggregation{
+			`metric`: agg.SubAggregation(`per_customer`, elasticorm.TermsAggregation(`Customer`, 10)),
+		})
+		equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+	}
+}
@@ -0,0 +1,38 @@
+package elasticorm
+
+import (
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+const (
+	// autocompleteSubField is the name of the sub field, which the autocomplete tag adds to a field
+	autocompleteSubField       = `autocomplete`
+	autocompleteAnalyzer       = `autocomplete`
+	autocompleteSearchAnalyzer = `autocomplete_search`
+	autocompleteTokenizer      = `autocomplete`
+)
+
+// autocompleteFieldConfig returns the mapping of the sub field, which indexes the edge ngrams of the words of a field
+func autocompleteFieldConfig() MappingFieldConfig {
+	return MappingFieldConfig{
+		Type:           `text`,
+		Analyzer:       autocompleteAnalyzer,
+		SearchAnalyzer: autocompleteSearchAnalyzer,
+	}
+}
+
+// Autocomplete finds up to limit documents with words in the field starting with the words of prefix - the best matches first.
+// The field must be tagged with elasticorm:"autocomplete"
+func (ds *Datastore) Autocomplete(fieldName string, prefix string, limit int, results interface{}, opts ...QueryOptFunc) error {
+	elasticFieldName, cfg, err := ds.IndexDefinition.elasticField(ds.typeName, fieldName)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Fields[autocompleteSubField]; !ok {
+		return errors.Wrapf(ErrNoAutocompleteField, `autocomplete on %s`, fieldName)
+	}
+	opts = append(append([]QueryOptFunc{}, opts...), ds.Limit(limit))
+	q := elastic.NewMatchQuery(elasticFieldName+`.`+autocompleteSubField, prefix).Operator(`and`)
+	return ds.FindQuery(results, q, opts...)
+}
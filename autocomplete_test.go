@@ -0,0 +1,37 @@
+package elasticorm_test
+
+import (
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestAutocomplete(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name" elasticorm:"autocomplete"`
+		City string `json:"city"`
+	}
+
+	_, ds := initDatastore(t, &User{})
+	for _, name := range []string{`Frederic Vosberg`, `Fred Astaire`, `Gopher`} {
+		err := ds.Create(&User{Name: name})
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	found := []User{}
+	err := ds.Autocomplete(`Name`, `fre`, 10, &found)
+	ok(t, err)
+	equals(t, 2, len(found))
+
+	found = []User{}
+	err = ds.Autocomplete(`Name`, `fred vos`, 10, &found)
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Frederic Vosberg`, found[0].Name)
+
+	err = ds.Autocomplete(`City`, `ber`, 10, &found)
+	equals(t, elasticorm.ErrNoAutocompleteField, errors.Cause(err))
+}
@@ -0,0 +1,167 @@
+package elasticorm
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// BulkResult is returned by the bulk methods of the datastore and lists the items, which couldn't be processed
+type BulkResult struct {
+	Failed []BulkItemError
+}
+
+// HasErrors returns wether at least one item of the bulk request failed
+func (r BulkResult) HasErrors() bool {
+	return len(r.Failed) > 0
+}
+
+// BulkItemError describes why an item of a bulk request failed
+type BulkItemError struct {
+	Position int         // of the item in the passed in slice
+	ID       string      // of the elasticsearch document
+	Item     interface{} // the passed in struct
+	Err      error
+}
+
+func (e BulkItemError) Error() string {
+	return fmt.Sprintf("bulk item %d (ID \"%s\") failed: %s", e.Position, e.ID, e.Err.Error())
+}
+
+// BulkCreate creates all structs of the passed in slice with one request. The generated IDs are set on the structs
+func (ds *Datastore) BulkCreate(items interface{}) (BulkResult, error) {
+	return ds.bulk(items, ds.bulkCreateRequest)
+}
+
+// BulkUpdate updates all structs of the passed in slice with one request. Like Update it respects the version field
+func (ds *Datastore) BulkUpdate(items interface{}) (BulkResult, error) {
+	return ds.bulk(items, ds.bulkUpdateRequest)
+}
+
+// BulkDelete deletes all structs of the passed in slice with one request
+func (ds *Datastore) BulkDelete(items interface{}) (BulkResult, error) {
+	return ds.bulk(items, ds.bulkDeleteRequest)
+}
+
+func (ds *Datastore) bulkCreateRequest(o interface{}, ID string) (elastic.BulkableRequest, error) {
+	r := elastic.NewBulkIndexRequest().
+		Index(ds.indexName).
+		Type(ds.typeName).
+		Doc(o)
+	if ID != `` {
+		r = r.Id(ID).OpType(`create`)
+	}
+	return r, nil
+}
+
+func (ds *Datastore) bulkUpdateRequest(o interface{}, ID string) (elastic.BulkableRequest, error) {
+	if ID == `` {
+		return nil, errors.New(`can't save struct with empty ID`)
+	}
+	r := elastic.NewBulkUpdateRequest().
+		Index(ds.indexName).
+		Type(ds.typeName).
+		Id(ID).
+		Doc(o)
+	if version := ds.getVersion(o); version > 0 {
+		r = r.Version(version)
+	}
+	return r, nil
+}
+
+func (ds *Datastore) bulkDeleteRequest(o interface{}, ID string) (elastic.BulkableRequest, error) {
+	if ID == `` {
+		return nil, errors.New(`can't delete struct with empty ID`)
+	}
+	return elastic.NewBulkDeleteRequest().
+		Index(ds.indexName).
+		Type(ds.typeName).
+		Id(ID), nil
+}
+
+type bulkRequestFunc func(o interface{}, ID string) (elastic.BulkableRequest, error)
+
+func (ds *Datastore) bulk(items interface{}, requestFor bulkRequestFunc) (BulkResult, error) {
+	result := BulkResult{}
+	itemsv := reflect.ValueOf(items)
+	if itemsv.Kind() != reflect.Slice {
+		return result, errors.Wrap(ErrInvalidType, `no slice given`)
+	}
+	if itemsv.Len() == 0 {
+		return result, nil
+	}
+
+	bs := ds.elasticClient.Bulk()
+	for n := 0; n < itemsv.Len(); n++ {
+		o := itemsv.Index(n).Interface()
+		err := ds.isSaveableType(o)
+		if err != nil {
+			return result, errors.Wrapf(err, `bulk item %d`, n)
+		}
+		ID, err := ds.getID(o)
+		if err != nil {
+			return result, errors.Wrapf(err, `bulk item %d`, n)
+		}
+		r, err := requestFor(o, ID)
+		if err != nil {
+			return result, errors.Wrapf(err, `bulk item %d`, n)
+		}
+		bs = bs.Add(r)
+	}
+
+	res, err := bs.Do(ds.Ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for n, item := range bulkResponseItems(res) {
+		if n >= itemsv.Len() {
+			break
+		}
+		o := itemsv.Index(n).Interface()
+		if err := bulkItemErr(item); err != nil {
+			result.Failed = append(result.Failed, BulkItemError{
+				Position: n,
+				ID:       item.Id,
+				Item:     o,
+				Err:      err,
+			})
+			continue
+		}
+		ds.setVersion(o, &item.Version)
+		ds.setID(o, item.Id)
+	}
+	return result, nil
+}
+
+// bulkResponseItems returns the items of a bulk response in the order of the requests
+func bulkResponseItems(res *elastic.BulkResponse) []*elastic.BulkResponseItem {
+	items := make([]*elastic.BulkResponseItem, 0, len(res.Items))
+	for _, actions := range res.Items {
+		for _, item := range actions {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// bulkItemErr translates the error of a bulk response item to the errors of this package
+func bulkItemErr(item *elastic.BulkResponseItem) error {
+	if item == nil {
+		return errors.New(`missing bulk response item`)
+	}
+	switch {
+	case item.Status == http.StatusNotFound:
+		return ErrNotFound
+	case item.Status == http.StatusConflict:
+		return ErrConflict
+	case item.Error != nil:
+		return errors.Errorf("%s: %s", item.Error.Type, item.Error.Reason)
+	case item.Status >= 300:
+		return errors.Errorf("unexpected status %d", item.Status)
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package elasticorm
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// BulkIndexer collects create, update and delete operations of many goroutines and sends them in batches to elasticsearch.
+// A batch is sent when it reaches the configured number of documents or bytes, or when the flush interval has passed.
+// A batch never exceeds the configured bytes, unless a single item is bigger, which is then sent on its own.
+// When the internal queue is full, adding an item blocks until there is space again.
+// An added struct belongs to the BulkIndexer until Close returns, because the ID and version are set on it in the background,
+// after its batch has been sent. Reading or changing it before is a data race
+type BulkIndexer struct {
+	ds            *Datastore
+	bulkActions   int
+	bulkSize      int
+	flushInterval time.Duration
+	queueSize     int
+	backoff       elastic.Backoff
+	onError       func(item interface{}, err error)
+
+	queue  chan *bulkIndexerItem
+	done   chan struct{} // closed by Close to cancel the backoff of rejected items
+	mutex  sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	// the failed items are passed to onError on an own goroutine, so that it can add items again, while the queue is full
+	failures     []bulkIndexerFailure
+	failureCond  *sync.Cond
+	failuresDone bool // no more failures are coming, because run has returned
+}
+
+// BulkIndexerOptFunc is used as a parameter to NewBulkIndexer and provides a way of configuration
+type BulkIndexerOptFunc func(*BulkIndexer) error
+
+type bulkIndexerItem struct {
+	o        interface{}
+	request  elastic.BulkableRequest
+	size     int
+	isCreate bool
+}
+
+type bulkIndexerFailure struct {
+	o   interface{}
+	err error
+}
+
+// NewBulkIndexer returns a running BulkIndexer for the datastore. It has to be closed with Close to send the remaining items
+func (ds *Datastore) NewBulkIndexer(opts ...BulkIndexerOptFunc) (*BulkIndexer, error) {
+	bi := &BulkIndexer{
+		ds:            ds,
+		bulkActions:   1000,
+		bulkSize:      5 << 20,
+		flushInterval: time.Second,
+		backoff:       elastic.NewExponentialBackoff(100*time.Millisecond, 10*time.Second),
+		onError:       func(interface{}, error) {},
+	}
+	for _, opt := range opts {
+		if err := opt(bi); err != nil {
+			return nil, err
+		}
+	}
+	if bi.queueSize == 0 {
+		bi.queueSize = bi.bulkActions
+	}
+	bi.queue = make(chan *bulkIndexerItem, bi.queueSize)
+	bi.done = make(chan struct{})
+	bi.failureCond = sync.NewCond(&sync.Mutex{})
+	bi.wg.Add(2)
+	go bi.run()
+	go bi.reportFailures()
+	return bi, nil
+}
+
+// BulkActions is a BulkIndexerOptFunc which sets the number of documents, after which a batch is sent
+func BulkActions(n int) BulkIndexerOptFunc {
+	return func(bi *BulkIndexer) error {
+		if n < 1 {
+			return errors.New(`bulk actions must be at least 1`)
+		}
+		bi.bulkActions = n
+		return nil
+	}
+}
+
+// BulkSize is a BulkIndexerOptFunc which sets the size in bytes, after which a batch is sent
+func BulkSize(bytes int) BulkIndexerOptFunc {
+	return func(bi *BulkIndexer) error {
+		if bytes < 1 {
+			return errors.New(`bulk size must be at least 1 byte`)
+		}
+		bi.bulkSize = bytes
+		return nil
+	}
+}
+
+// BulkFlushInterval is a BulkIndexerOptFunc which sets the interval, after which a batch is sent regardless of its size
+func BulkFlushInterval(d time.Duration) BulkIndexerOptFunc {
+	return func(bi *BulkIndexer) error {
+		if d <= 0 {
+			return errors.New(`flush interval must be positive`)
+		}
+		bi.flushInterval = d
+		return nil
+	}
+}
+
+// BulkQueueSize is a BulkIndexerOptFunc which sets the number of items, which can be queued before adding blocks
+func BulkQueueSize(n int) BulkIndexerOptFunc {
+	return func(bi *BulkIndexer) error {
+		if n < 1 {
+			return errors.New(`queue size must be at least 1`)
+		}
+		bi.queueSize = n
+		return nil
+	}
+}
+
+// BulkBackoff is a BulkIndexerOptFunc which sets the backoff used for retrying rejected items
+func BulkBackoff(b elastic.Backoff) BulkIndexerOptFunc {
+	return func(bi *BulkIndexer) error {
+		bi.backoff = b
+		return nil
+	}
+}
+
+// BulkErrorHandler is a BulkIndexerOptFunc which sets the callback for failed items. It is called with the originally added struct.
+// The callback runs on an own goroutine and may add the item again, e.g. to retry it. After Close has been called, adding fails with ErrBulkIndexerClosed
+func BulkErrorHandler(fn func(item interface{}, err error)) BulkIndexerOptFunc {
+	return func(bi *BulkIndexer) error {
+		bi.onError = fn
+		return nil
+	}
+}
+
+// Create adds the struct to be created. A generated ID and the version are set on the struct, after its batch has been sent.
+// The struct must not be used until Close returns
+func (bi *BulkIndexer) Create(o interface{}) error {
+	return bi.add(o, bi.ds.bulkCreateRequest, true)
+}
+
+// Update adds the struct to be updated. The new version is set on the struct, after its batch has been sent.
+// The struct must not be used until Close returns
+func (bi *BulkIndexer) Update(o interface{}) error {
+	return bi.add(o, bi.ds.bulkUpdateRequest, false)
+}
+
+// Delete adds the struct to be deleted. The struct must not be used until Close returns
+func (bi *BulkIndexer) Delete(o interface{}) error {
+	return bi.add(o, bi.ds.bulkDeleteRequest, false)
+}
+
+// Close sends all queued items and waits until they are processed. Afterwards the added structs can be used again.
+// Rejected items aren't retried any longer, but passed to the error handler
+func (bi *BulkIndexer) Close() error {
+	bi.mutex.Lock()
+	if bi.closed {
+		bi.mutex.Unlock()
+		return ErrBulkIndexerClosed
+	}
+	bi.closed = true
+	close(bi.done)
+	close(bi.queue)
+	bi.mutex.Unlock()
+
+	bi.wg.Wait()
+	return nil
+}
+
+func (bi *BulkIndexer) add(o interface{}, requestFor bulkRequestFunc, isCreate bool) error {
+	err := bi.ds.isSaveableType(o)
+	if err != nil {
+		return err
+	}
+	ID, err := bi.ds.getID(o)
+	if err != nil {
+		return err
+	}
+	r, err := requestFor(o, ID)
+	if err != nil {
+		return err
+	}
+	lines, err := r.Source()
+	if err != nil {
+		return err
+	}
+	size := 0
+	for _, line := range lines {
+		size += len(line) + 1
+	}
+
+	bi.mutex.RLock()
+	defer bi.mutex.RUnlock()
+	if bi.closed {
+		return ErrBulkIndexerClosed
+	}
+	bi.queue <- &bulkIndexerItem{o: o, request: r, size: size, isCreate: isCreate}
+	return nil
+}
+
+func (bi *BulkIndexer) run() {
+	defer bi.wg.Done()
+	defer bi.finishFailures()
+	ticker := time.NewTicker(bi.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*bulkIndexerItem, 0, bi.bulkActions)
+	size := 0
+	for {
+		select {
+		case item, ok := <-bi.queue:
+			if !ok {
+				bi.flush(batch)
+				return
+			}
+			if len(batch) > 0 && size+item.size > bi.bulkSize {
+				bi.flush(batch)
+				batch = batch[:0]
+				size = 0
+			}
+			batch = append(batch, item)
+			size += item.size
+			if len(batch) >= bi.bulkActions || size >= bi.bulkSize {
+				bi.flush(batch)
+				batch = batch[:0]
+				size = 0
+			}
+		case <-ticker.C:
+			bi.flush(batch)
+			batch = batch[:0]
+			size = 0
+		}
+	}
+}
+
+// flush sends the batch and retries rejected items with backoff until the backoff gives up, the indexer is closed or the context is done
+func (bi *BulkIndexer) flush(batch []*bulkIndexerItem) {
+	for retry := 0; len(batch) > 0; retry++ {
+		rejected, err := bi.send(batch)
+		if err != nil {
+			rejected = batch
+		}
+		if len(rejected) == 0 {
+			return
+		}
+		wait, ok := bi.backoff.Next(retry)
+		if !ok {
+			if err == nil {
+				err = errors.New(`bulk item rejected by elasticsearch`)
+			}
+			bi.failAll(rejected, err)
+			return
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-bi.done:
+			timer.Stop()
+			bi.failAll(rejected, errors.Wrap(ErrBulkIndexerClosed, `retry of rejected item canceled`))
+			return
+		case <-bi.ds.Ctx.Done():
+			timer.Stop()
+			bi.failAll(rejected, bi.ds.Ctx.Err())
+			return
+		}
+		batch = rejected
+	}
+}
+
+// send executes one bulk request and returns the items, which have been rejected and should be retried
+func (bi *BulkIndexer) send(batch []*bulkIndexerItem) ([]*bulkIndexerItem, error) {
+	bs := bi.ds.elasticClient.Bulk()
+	for _, item := range batch {
+		bs = bs.Add(item.request)
+	}
+	res, err := bs.Do(bi.ds.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rejected := make([]*bulkIndexerItem, 0)
+	for n, resItem := range bulkResponseItems(res) {
+		if n >= len(batch) {
+			break
+		}
+		item := batch[n]
+		if resItem != nil && resItem.Status == http.StatusTooManyRequests {
+			rejected = append(rejected, item)
+			continue
+		}
+		if err := bulkItemErr(resItem); err != nil {
+			bi.fail(item.o, err)
+			continue
+		}
+		if item.isCreate {
+			bi.ds.setID(item.o, resItem.Id)
+		}
+		bi.ds.setVersion(item.o, &resItem.Version)
+	}
+	return rejected, nil
+}
+
+// fail queues the item for the error handler without blocking
+func (bi *BulkIndexer) fail(o interface{}, err error) {
+	bi.failureCond.L.Lock()
+	bi.failures = append(bi.failures, bulkIndexerFailure{o: o, err: err})
+	bi.failureCond.L.Unlock()
+	bi.failureCond.Signal()
+}
+
+func (bi *BulkIndexer) failAll(items []*bulkIndexerItem, err error) {
+	for _, item := range items {
+		bi.fail(item.o, err)
+	}
+}
+
+func (bi *BulkIndexer) finishFailures() {
+	bi.failureCond.L.Lock()
+	bi.failuresDone = true
+	bi.failureCond.L.Unlock()
+	bi.failureCond.Signal()
+}
+
+// reportFailures passes the failed items to the error handler until run has returned and all failures are reported
+func (bi *BulkIndexer) reportFailures() {
+	defer bi.wg.Done()
+	for {
+		bi.failureCond.L.Lock()
+		for len(bi.failures) == 0 && !bi.failuresDone {
+			bi.failureCond.Wait()
+		}
+		failures, done := bi.failures, bi.failuresDone
+		bi.failures = nil
+		bi.failureCond.L.Unlock()
+
+		for _, f := range failures {
+			bi.onError(f.o, f.err)
+		}
+		if done && len(failures) == 0 {
+			return
+		}
+	}
+}
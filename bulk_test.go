@@ -0,0 +1,165 @@
+package elasticorm_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fvosberg/elasticorm"
+)
+
+func TestDatastoreBulkCreateUpdateDelete(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name" elasticorm:"sortable"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	users := []*User{
+		&User{Name: `Unknown No. 1`},
+		&User{Name: `Unknown No. 2`},
+		&User{ID: `fixed-id`, Name: `Unknown No. 3`},
+	}
+	res, err := ds.BulkCreate(users)
+	ok(t, err)
+	assert(t, !res.HasErrors(), `BulkCreate should not fail: %#v`, res.Failed)
+	for _, u := range users {
+		assert(t, u.ID != ``, `The ID of the user should be set after persisting`)
+	}
+	equals(t, `fixed-id`, users[2].ID)
+	ds.Refresh()
+
+	users[0].Name = `Changed No. 1`
+	res, err = ds.BulkUpdate([]*User{users[0], &User{ID: `unknown-id`, Name: `Unknown`}})
+	ok(t, err)
+	equals(t, 1, len(res.Failed))
+	equals(t, 1, res.Failed[0].Position)
+	equals(t, elasticorm.ErrNotFound, res.Failed[0].Err)
+
+	gotUser := User{}
+	err = ds.Find(users[0].ID, &gotUser)
+	ok(t, err)
+	equals(t, `Changed No. 1`, gotUser.Name)
+
+	res, err = ds.BulkDelete(users[1:])
+	ok(t, err)
+	assert(t, !res.HasErrors(), `BulkDelete should not fail: %#v`, res.Failed)
+	ds.Refresh()
+
+	found := []User{}
+	err = ds.FindAll(&found)
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Changed No. 1`, found[0].Name)
+}
+
+func TestDatastoreBulkCreateWithInvalidType(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name"`
+	}
+	type Admin struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	_, err := ds.BulkCreate([]*Admin{&Admin{Name: `Admin`}})
+	assert(t, err != nil, `BulkCreate should fail for a slice of another type`)
+}
+
+func TestBulkIndexer(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name" elasticorm:"sortable"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	failed := make(chan interface{}, 100)
+	bi, err := ds.NewBulkIndexer(
+		elasticorm.BulkActions(10),
+		elasticorm.BulkFlushInterval(100*time.Millisecond),
+		elasticorm.BulkErrorHandler(func(item interface{}, err error) {
+			failed <- item
+		}),
+	)
+	ok(t, err)
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 5; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				err := bi.Create(&User{Name: fmt.Sprintf("Unknown No. %d-%d", g, i)})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	unknown := &User{ID: `unknown-id`, Name: `Unknown`}
+	err = bi.Update(unknown)
+	ok(t, err)
+
+	err = bi.Close()
+	ok(t, err)
+	equals(t, elasticorm.ErrBulkIndexerClosed, bi.Create(&User{Name: `Too late`}))
+	ds.Refresh()
+
+	close(failed)
+	failedItems := []interface{}{}
+	for item := range failed {
+		failedItems = append(failedItems, item)
+	}
+	equals(t, []interface{}{unknown}, failedItems)
+
+	found := []User{}
+	err = ds.FindAll(&found, ds.Limit(100))
+	ok(t, err)
+	equals(t, 25, len(found))
+}
+
+func TestBulkIndexerReAddInErrorHandler(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	// the unknown users can't be updated, so the error handler creates them instead
+	var bi *elasticorm.BulkIndexer
+	readded := make(chan error, 10)
+	bi, err := ds.NewBulkIndexer(
+		elasticorm.BulkActions(1),
+		elasticorm.BulkQueueSize(1),
+		elasticorm.BulkErrorHandler(func(item interface{}, err error) {
+			u := item.(*User)
+			readded <- bi.Create(&User{ID: u.ID, Name: u.Name})
+		}),
+	)
+	ok(t, err)
+
+	for i := 0; i < 5; i++ {
+		err := bi.Update(&User{ID: fmt.Sprintf("unknown-%d", i), Name: `Unknown`})
+		ok(t, err)
+	}
+	for i := 0; i < 5; i++ {
+		select {
+		case err := <-readded:
+			ok(t, err)
+		case <-time.After(10 * time.Second):
+			t.Fatal(`The error handler should be able to add items again`)
+		}
+	}
+	err = bi.Close()
+	ok(t, err)
+	ds.Refresh()
+
+	found := []User{}
+	err = ds.FindAll(&found)
+	ok(t, err)
+	equals(t, 5, len(found))
+}
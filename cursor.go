@@ -0,0 +1,77 @@
+package elasticorm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// tieBreakerField is sorted on as last criteria, so that documents with equal sort values have a stable order
+const tieBreakerField = `_uid`
+
+// After continues a search after the document the cursor has been returned for (see Page.NextCursor).
+// The search must have the same sorting as the one which returned the cursor. Unlike Offset it works for deep pagination,
+// but it can't be combined with an Offset
+func (ds *Datastore) After(cursor string) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		values, err := decodeCursor(cursor)
+		if err != nil {
+			return err
+		}
+		srv.SearchAfter(values...)
+		ds.searches.update(srv, func(sq *searchQuery) {
+			sq.cursor = true
+			sq.after = true
+		})
+		return nil
+	}
+}
+
+// withCursor makes the search sortable by cursors and fetches one additional hit, which tells if there is a next page
+func (ds *Datastore) withCursor() QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		ds.searches.update(srv, func(sq *searchQuery) {
+			sq.cursor = true
+			sq.peek = true
+		})
+		return nil
+	}
+}
+
+// addTieBreakerSort adds the sorting by the ID, which makes the cursors unambiguous. Without a sorting the documents are sorted by score first
+func addTieBreakerSort(srv *elastic.SearchService, sq *searchQuery) {
+	if !sq.sorted {
+		srv.SortBy(elastic.NewScoreSort().Desc())
+	}
+	srv.Sort(tieBreakerField, true)
+}
+
+// encodeCursor encodes the sort values of a hit as URL safe string
+func encodeCursor(sortValues []interface{}) (string, error) {
+	if len(sortValues) == 0 {
+		return ``, nil
+	}
+	JSON, err := json.Marshal(sortValues)
+	if err != nil {
+		return ``, err
+	}
+	return base64.RawURLEncoding.EncodeToString(JSON), nil
+}
+
+func decodeCursor(cursor string) ([]interface{}, error) {
+	JSON, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidCursor, err.Error())
+	}
+	// numbers are kept as json.Number, because long sort values like dates would lose precision as float64
+	dec := json.NewDecoder(bytes.NewReader(JSON))
+	dec.UseNumber()
+	values := []interface{}{}
+	if err := dec.Decode(&values); err != nil || len(values) == 0 {
+		return nil, errors.Wrap(ErrInvalidCursor, `cursor contains no sort values`)
+	}
+	return values, nil
+}
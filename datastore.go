@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/olivere/elastic"
 	"github.com/pkg/errors"
@@ -52,13 +53,19 @@ type QueryOptFunc func(*elastic.SearchService) error
 // It is meant to be used for one struct and helps storing and retrieving it in/from elasticsearch
 // It leverages the great elastic package from olivere
 type Datastore struct {
-	elasticClient   *elastic.Client
-	Ctx             context.Context
-	indexName       string
-	goType          reflect.Type
-	idFieldName     string          // the name of the structs field to store the ID
-	typeName        string          // in elasticsearch
-	IndexDefinition IndexDefinition // in elasticsearch
+	elasticClient       *elastic.Client
+	Ctx                 context.Context
+	indexName           string
+	goType              reflect.Type
+	searches            searchRegistry
+	idFieldName         string            // the name of the structs field to store the ID
+	versionFieldName    string            // the name of the structs field to store the document version
+	facetFieldNames     []string          // the names of the structs fields tagged as facet
+	highlightsFieldName string            // the name of the structs field to store the highlights of a search
+	metaFieldNames      map[string]string // the names of the structs fields tagged with meta by the kind of metadata
+	searchableFields    []searchableField // the structs fields tagged as searchable
+	typeName            string            // in elasticsearch
+	IndexDefinition     IndexDefinition   // in elasticsearch
 }
 
 // EnsureIndexExists checks wether the needed index for this datastore exists. It it doesn't it gets created
@@ -155,7 +162,15 @@ func ForStruct(i interface{}) DatastoreOptFunc {
 		if ds.idFieldName == "" {
 			ds.idFieldName = "ID"
 		}
-		return nil
+		ds.versionFieldName = fieldNameWithOption(ds.goType, `version`)
+		ds.facetFieldNames = fieldNamesWithOption(ds.goType, `facet`)
+		ds.highlightsFieldName = fieldNameWithOption(ds.goType, `highlights`)
+		ds.metaFieldNames, err = metaFieldNames(ds.goType)
+		if err != nil {
+			return err
+		}
+		ds.searchableFields, err = searchableFieldsForType(ds.goType)
+		return err
 	}
 }
 
@@ -213,6 +228,7 @@ func (ds *Datastore) Create(o interface{}, opts ...IndexOptFunc) error {
 	if put.Result != "created" {
 		return ErrCreationFailed
 	}
+	ds.setVersion(o, &put.Version)
 	return ds.setID(o, put.Id)
 }
 
@@ -252,7 +268,7 @@ func (ds *Datastore) Find(ID string, result interface{}) error {
 		return err
 	}
 
-	return ds.DecodeElasticResponse(res.Source, res.Id, result)
+	return ds.decodeQueryResult(getToResult(res), result)
 }
 
 func (ds *Datastore) FindByIDs(IDs []string, result interface{}) error {
@@ -279,167 +295,165 @@ func (ds *Datastore) Update(o interface{}) error {
 	if ID == `` {
 		return errors.New(`can't save struct with empty ID`)
 	}
-	_, err = ds.elasticClient.Update().
+	us := ds.elasticClient.Update().
 		Index(ds.indexName).
 		Type(ds.typeName).
 		Id(ID).
-		Doc(o).
-		Do(ds.Ctx)
+		Doc(o)
 
-	return err
-}
+	// with a version field the update is only applied, when the document hasn't been changed in the meantime
+	if version := ds.getVersion(o); version > 0 {
+		us = us.Version(version)
+	}
 
-func (ds *Datastore) FindOneBy(fieldName string, value interface{}, result interface{}, opts ...QueryOptFunc) error {
-	elasticFieldName, err := ds.IndexDefinition.elasticFieldName(ds.typeName, fieldName)
+	res, err := us.Do(ds.Ctx)
+	if elastic.IsConflict(err) {
+		return ErrConflict
+	}
 	if err != nil {
 		return err
 	}
-	q := ds.elasticClient.Search().
-		Index(ds.indexName).
-		Query(elastic.NewBoolQuery().Filter(elastic.NewTermQuery(elasticFieldName, value))).
-		From(0).Size(1)
+	ds.setVersion(o, &res.Version)
+	return nil
+}
 
-	for _, opt := range opts {
-		err := opt(q)
-		if err != nil {
-			return err
-		}
+// Upsert updates the document with the ID of the passed in struct or creates it, when it doesn't exist yet.
+// A struct without an ID is always created and gets the generated ID. The returned boolean reports wether the document has been created
+func (ds *Datastore) Upsert(o interface{}) (bool, error) {
+	err := ds.isSaveableType(o)
+	if err != nil {
+		return false, err
 	}
-
-	res, err := q.Do(ds.Ctx)
+	ID, err := ds.getID(o)
 	if err != nil {
-		return err
+		return false, err
 	}
+	if ID == `` {
+		return true, ds.Create(o)
+	}
+	res, err := ds.elasticClient.Update().
+		Index(ds.indexName).
+		Type(ds.typeName).
+		Id(ID).
+		Doc(o).
+		DocAsUpsert(true).
+		Do(ds.Ctx)
 
-	if res.TotalHits() < 1 {
-		return ErrNotFound
+	if err != nil {
+		return false, err
 	}
-	return ds.DecodeElasticResponse(res.Hits.Hits[0].Source, res.Hits.Hits[0].Id, result)
+	ds.setVersion(o, &res.Version)
+	return res.Result == `created`, ds.setID(o, res.Id)
 }
 
-/*
-func (ds *Datastore) Search(q interface{}, result interface{}) error {
-	query := ds.elasticClient.Search().
-		Index(ds.indexName).
-		Query(q)
-
-	res, err := q.Do(ds.Ctx)
+// Delete removes the passed in struct from elasticsearch. The document is identified by the ID field of the struct
+func (ds *Datastore) Delete(o interface{}) error {
+	err := ds.isSaveableType(o)
 	if err != nil {
 		return err
 	}
-
-	if res.TotalHits() < 1 {
-		return ErrNotFound
+	ID, err := ds.getID(o)
+	if err != nil {
+		return err
 	}
-	return ds.DecodeElasticResponse(res.Hits.Hits[0].Source, res.Hits.Hits[0].Id, result)
+	if ID == `` {
+		return errors.New(`can't delete struct with empty ID`)
+	}
+	return ds.DeleteByID(ID)
 }
-*/
 
-func (ds *Datastore) FindAll(results interface{}, opts ...QueryOptFunc) error {
-	q := ds.elasticClient.Search().
+// DeleteByID removes the document with the given ID. It returns ErrNotFound, when there is no such document
+func (ds *Datastore) DeleteByID(ID string) error {
+	res, err := ds.elasticClient.Delete().
 		Index(ds.indexName).
 		Type(ds.typeName).
-		Query(elastic.NewMatchAllQuery())
-
-	for _, opt := range opts {
-		err := opt(q)
-		if err != nil {
-			return err
-		}
-	}
+		Id(ID).
+		Do(ds.Ctx)
 
-	res, err := q.Do(ds.Ctx)
-	if err != nil {
-		return err
+	if elastic.IsNotFound(err) || (res != nil && res.Result == `not_found`) {
+		return ErrNotFound
 	}
-	return ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
+	return err
 }
 
-func (ds *Datastore) FindFiltered(results interface{}, mustFilters map[string]interface{}, opts ...QueryOptFunc) error {
-	filter := elastic.NewBoolQuery()
-	for name, value := range mustFilters {
-		filter = filter.Must(elastic.NewTermQuery(name, value))
+// DeleteFiltered removes all documents matching the filters. The keys of the filters are the names of the struct fields.
+// Without filters it fails with ErrNoFilters instead of removing all documents
+func (ds *Datastore) DeleteFiltered(filters map[string]interface{}) error {
+	if len(filters) == 0 {
+		return ErrNoFilters
 	}
-
-	q := ds.elasticClient.Search().
-		Index(ds.indexName).
-		Type(ds.typeName).
-		Query(filter)
-
-	for _, opt := range opts {
-		err := opt(q)
+	filter := elastic.NewBoolQuery()
+	for fieldName, value := range filters {
+		elasticFieldName, err := ds.IndexDefinition.elasticFieldName(ds.typeName, fieldName)
 		if err != nil {
 			return err
 		}
+		filter = filter.Filter(elastic.NewTermQuery(elasticFieldName, value))
 	}
 
-	res, err := q.Do(ds.Ctx)
-	if err != nil {
-		return err
-	}
-	return ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
-}
-
-func (ds *Datastore) FindNestedFiltered(results interface{}, path string, mustFilters map[string]string, opts ...QueryOptFunc) error {
-	q := ds.elasticClient.Search().
-		Index(ds.indexName).
+	_, err := ds.elasticClient.DeleteByQuery(ds.indexName).
 		Type(ds.typeName).
-		Query(elastic.NewNestedQuery(path, filterQuery(mustFilters)))
+		Query(filter).
+		Do(ds.Ctx)
 
-	for _, opt := range opts {
-		err := opt(q)
-		if err != nil {
-			return err
-		}
-	}
+	return err
+}
 
-	res, err := q.Do(ds.Ctx)
+func (ds *Datastore) FindOneBy(fieldName string, value interface{}, result interface{}, opts ...QueryOptFunc) error {
+	elasticFieldName, err := ds.IndexDefinition.elasticFieldName(ds.typeName, fieldName)
+	if err != nil {
+		return err
+	}
+	opts = append([]QueryOptFunc{ds.Offset(0), ds.Limit(1)}, opts...)
+	res, _, err := ds.executeSearch(elastic.NewBoolQuery().Filter(elastic.NewTermQuery(elasticFieldName, value)), opts)
 	if err != nil {
 		return err
 	}
-	return ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
-}
-
-func (ds *Datastore) FindQuery(results interface{}, q elastic.Query, opts ...QueryOptFunc) error {
-	s := ds.elasticClient.Search().
-		Index(ds.indexName).
-		Type(ds.typeName).
-		Query(q)
 
-	for _, opt := range opts {
-		err := opt(s)
-		if err != nil {
-			return err
-		}
+	if res.TotalHits() < 1 {
+		return ErrNotFound
 	}
+	return ds.decodeQueryResult(hitToResult(res.Hits.Hits[0]), result)
+}
 
-	res, err := s.Do(ds.Ctx)
+// Search executes the Query and decodes the found documents into results, which must be a pointer to a slice
+func (ds *Datastore) Search(query Query, results interface{}, opts ...QueryOptFunc) error {
+	q, err := query.elasticQuery(ds.resolveFieldName)
 	if err != nil {
 		return err
 	}
-	return ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
+	return ds.FindQuery(results, q, opts...)
 }
 
-func (ds *Datastore) FindNestedQuery(results interface{}, path string, nested elastic.Query, opts ...QueryOptFunc) error {
-	q := ds.elasticClient.Search().
-		Index(ds.indexName).
-		Type(ds.typeName).
-		Query(elastic.NewNestedQuery(path, nested))
+func (ds *Datastore) FindAll(results interface{}, opts ...QueryOptFunc) error {
+	return ds.FindQuery(results, elastic.NewMatchAllQuery(), opts...)
+}
 
-	for _, opt := range opts {
-		err := opt(q)
-		if err != nil {
-			return err
-		}
+func (ds *Datastore) FindFiltered(results interface{}, mustFilters map[string]interface{}, opts ...QueryOptFunc) error {
+	filter := elastic.NewBoolQuery()
+	for name, value := range mustFilters {
+		filter = filter.Must(elastic.NewTermQuery(name, value))
 	}
 
-	res, err := q.Do(ds.Ctx)
+	return ds.FindQuery(results, filter, opts...)
+}
+
+func (ds *Datastore) FindNestedFiltered(results interface{}, path string, mustFilters map[string]string, opts ...QueryOptFunc) error {
+	return ds.FindQuery(results, elastic.NewNestedQuery(path, filterQuery(mustFilters)), opts...)
+}
+
+func (ds *Datastore) FindQuery(results interface{}, q elastic.Query, opts ...QueryOptFunc) error {
+	res, _, err := ds.executeSearch(q, opts)
 	if err != nil {
 		return err
 	}
 	return ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
 }
 
+func (ds *Datastore) FindNestedQuery(results interface{}, path string, nested elastic.Query, opts ...QueryOptFunc) error {
+	return ds.FindQuery(results, elastic.NewNestedQuery(path, nested), opts...)
+}
+
 func (ds *Datastore) ScriptUpdate(script string, params map[string]interface{}, filter map[string]string) error {
 	_, err := ds.UpdateByQueryService().
 		Script(
@@ -486,12 +500,19 @@ func (ds *Datastore) SetSorting(fieldName string, order string) QueryOptFunc {
 		if order != `asc` && order != `desc` {
 			return errors.New(`sorting order must be asc or desc`)
 		}
-		elasticFieldName, err := ds.IndexDefinition.elasticFieldName(ds.typeName, fieldName)
+		elasticFieldName, cfg, err := ds.IndexDefinition.elasticField(ds.typeName, fieldName)
 		// TODO loosen coupling with indexDefinition
 		if err != nil {
 			return err
 		}
-		srv.Sort(elasticFieldName+`.raw`, order == `asc`)
+		sortField, err := sortFieldName(elasticFieldName, cfg)
+		if err != nil {
+			return err
+		}
+		srv.Sort(sortField, order == `asc`)
+		ds.searches.update(srv, func(sq *searchQuery) {
+			sq.sorted = true
+		})
 		return nil
 	}
 }
@@ -503,16 +524,80 @@ func (ds *Datastore) FilterByField(fieldName string, value interface{}) QueryOpt
 		if err != nil {
 			return err
 		}
-		q := elastic.NewBoolQuery()
-		q.Filter(elastic.NewTermQuery(elasticFieldName, value))
-		srv.Query(q)
-		return nil
+		return ds.addFilter(srv, elastic.NewTermQuery(elasticFieldName, value))
 	}
 }
 
+// FilterRange filters for documents with a value of the field between gte and lte (both inclusive). A nil bound is omitted.
+// Fields mapped as date accept time.Time values, numeric fields accept numeric go types
+func (ds *Datastore) FilterRange(fieldName string, gte, lte interface{}) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		q, err := ds.rangeQuery(fieldName, gte, lte)
+		if err != nil {
+			return err
+		}
+		return ds.addFilter(srv, q)
+	}
+}
+
+func (ds *Datastore) rangeQuery(fieldName string, gte, lte interface{}) (*elastic.RangeQuery, error) {
+	if gte == nil && lte == nil {
+		return nil, errors.Wrap(ErrInvalidValue, `range needs at least one bound`)
+	}
+	elasticFieldName, mapping, err := ds.IndexDefinition.elasticField(ds.typeName, fieldName)
+	if err != nil {
+		return nil, err
+	}
+	q := elastic.NewRangeQuery(elasticFieldName)
+	if mapping.Type == `date` {
+		q = q.Format(`epoch_millis`)
+	}
+	if gte != nil {
+		v, err := rangeValue(mapping.Type, gte)
+		if err != nil {
+			return nil, errors.Wrapf(err, `lower bound of %s`, fieldName)
+		}
+		q = q.Gte(v)
+	}
+	if lte != nil {
+		v, err := rangeValue(mapping.Type, lte)
+		if err != nil {
+			return nil, errors.Wrapf(err, `upper bound of %s`, fieldName)
+		}
+		q = q.Lte(v)
+	}
+	return q, nil
+}
+
+// rangeValue checks the value against the mapped elasticsearch type and returns the value to use in a range query
+func rangeValue(elasticType string, value interface{}) (interface{}, error) {
+	switch elasticType {
+	case `date`:
+		switch t := value.(type) {
+		case time.Time:
+			return t.UnixNano() / int64(time.Millisecond), nil
+		case *time.Time:
+			if t != nil {
+				return t.UnixNano() / int64(time.Millisecond), nil
+			}
+		}
+	case `long`, `integer`, `short`, `byte`, `double`, `float`, `half_float`, `scaled_float`:
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return value, nil
+		}
+	}
+	return nil, errors.Wrapf(ErrInvalidValue, `%T can't be used for a range on a %s field`, value, elasticType)
+}
+
 func (ds *Datastore) Offset(offset int) QueryOptFunc {
 	return func(srv *elastic.SearchService) error {
 		srv.From(offset)
+		ds.searches.update(srv, func(sq *searchQuery) {
+			sq.offset = offset
+		})
 		return nil
 	}
 }
@@ -520,6 +605,9 @@ func (ds *Datastore) Offset(offset int) QueryOptFunc {
 func (ds *Datastore) Limit(limit int) QueryOptFunc {
 	return func(srv *elastic.SearchService) error {
 		srv.Size(limit)
+		ds.searches.update(srv, func(sq *searchQuery) {
+			sq.limit = limit
+		})
 		return nil
 	}
 }
@@ -541,23 +629,7 @@ func NewBoundingBox(top, right, bottom, left float64) BoundingBox {
 }
 
 func (ds *Datastore) DoSearch(query elastic.Query, results interface{}, opts ...QueryOptFunc) error {
-	search := ds.elasticClient.Search().
-		Index(ds.indexName).
-		Query(query)
-
-	for _, opt := range opts {
-		err := opt(search)
-		if err != nil {
-			return err
-		}
-	}
-	res, err := search.Do(ds.Ctx)
-
-	if err != nil {
-		return err
-	}
-
-	return ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
+	return ds.FindQuery(results, query, opts...)
 }
 
 func (ds *Datastore) FindByGeoBoundingBox(fieldName string, box BoundingBox, results interface{}, opts ...QueryOptFunc) error {
@@ -569,26 +641,12 @@ func (ds *Datastore) FindByGeoBoundingBox(fieldName string, box BoundingBox, res
 		TopLeft(box.Top, box.Left).
 		BottomRight(box.Bottom, box.Right)
 
-	search := ds.elasticClient.Search().
-		Index(ds.indexName).
-		Query(elastic.NewBoolQuery().Filter(query))
-
-	for _, opt := range opts {
-		err := opt(search)
-		if err != nil {
-			return err
-		}
-	}
-	res, err := search.Do(ds.Ctx)
-
-	if err != nil {
-		return err
-	}
-
-	return ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
+	return ds.FindQuery(results, elastic.NewBoolQuery().Filter(query), opts...)
 }
 
-func (ds *Datastore) FindByGeoDistance(fieldName string, lat float64, lon float64, distance string, results interface{}) error {
+// FindByGeoDistance finds the documents within the distance around the point - the nearest first.
+// A sorting passed in the opts only orders documents with the same distance
+func (ds *Datastore) FindByGeoDistance(fieldName string, lat float64, lon float64, distance string, results interface{}, opts ...QueryOptFunc) error {
 	elasticFieldName, err := ds.IndexDefinition.elasticFieldName(ds.typeName, fieldName)
 	if err != nil {
 		return err
@@ -598,18 +656,8 @@ func (ds *Datastore) FindByGeoDistance(fieldName string, lat float64, lon float6
 		Lon(lon).
 		Distance(distance)
 
-	res, err := ds.elasticClient.Search().
-		Index(ds.indexName).
-		// TODO query type
-		Query(query).
-		SortBy(elastic.NewGeoDistanceSort(elasticFieldName).Point(lat, lon)).
-		Do(ds.Ctx)
-
-	if err != nil {
-		return err
-	}
-
-	return ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
+	opts = append([]QueryOptFunc{ds.sortByGeoDistance(elasticFieldName, lat, lon)}, opts...)
+	return ds.FindQuery(results, elastic.NewBoolQuery().Filter(query), opts...)
 }
 
 type QueryResult interface {
@@ -617,9 +665,24 @@ type QueryResult interface {
 	Source() *json.RawMessage
 }
 
+// metadataResult is implemented by the QueryResults of the datastore, which carry the document metadata
+type metadataResult interface {
+	Metadata() Metadata
+}
+
+// Metadata is what elasticsearch returns about a document besides its ID and source
+type Metadata struct {
+	Index      string
+	Score      *float64
+	Version    *int64
+	Sort       []interface{}
+	Highlights map[string][]string // by elasticsearch field name
+}
+
 type queryResult struct {
 	id     string
 	source *json.RawMessage
+	meta   Metadata
 }
 
 func (r queryResult) ID() string {
@@ -630,24 +693,47 @@ func (r queryResult) Source() *json.RawMessage {
 	return r.source
 }
 
+func (r queryResult) Metadata() Metadata {
+	return r.meta
+}
+
+func hitToResult(hit *elastic.SearchHit) QueryResult {
+	return queryResult{
+		id:     hit.Id,
+		source: hit.Source,
+		meta: Metadata{
+			Index:      hit.Index,
+			Score:      hit.Score,
+			Version:    hit.Version,
+			Sort:       hit.Sort,
+			Highlights: hit.Highlight,
+		},
+	}
+}
+
 func hitsToResults(hits []*elastic.SearchHit) []QueryResult {
 	res := make([]QueryResult, len(hits))
 	for i, hit := range hits {
-		res[i] = queryResult{
-			id:     hit.Id,
-			source: hit.Source,
-		}
+		res[i] = hitToResult(hit)
 	}
 	return res
 }
 
+func getToResult(get *elastic.GetResult) QueryResult {
+	return queryResult{
+		id:     get.Id,
+		source: get.Source,
+		meta: Metadata{
+			Index:   get.Index,
+			Version: get.Version,
+		},
+	}
+}
+
 func getsToResults(gets []*elastic.GetResult) []QueryResult {
 	res := make([]QueryResult, len(gets))
 	for i, get := range gets {
-		res[i] = queryResult{
-			id:     get.Id,
-			source: get.Source,
-		}
+		res[i] = getToResult(get)
 	}
 	return res
 }
@@ -663,7 +749,7 @@ func (ds *Datastore) DecodeElasticResponses(qrs []QueryResult, results interface
 
 	for _, qr := range qrs {
 		elemp := reflect.New(elemt)
-		err := ds.DecodeElasticResponse(qr.Source(), qr.ID(), elemp.Interface())
+		err := ds.decodeQueryResult(qr, elemp.Interface())
 		if err != nil {
 			return err
 		}
@@ -674,12 +760,29 @@ func (ds *Datastore) DecodeElasticResponses(qrs []QueryResult, results interface
 	return nil
 }
 
+// decodeQueryResult decodes the query result into the struct (pointer) o, including the document metadata
+func (ds *Datastore) decodeQueryResult(qr QueryResult, o interface{}) error {
+	err := ds.DecodeElasticResponse(qr.Source(), qr.ID(), o)
+	if err != nil {
+		return err
+	}
+	mr, ok := qr.(metadataResult)
+	if !ok {
+		return nil
+	}
+	meta := mr.Metadata()
+	ds.setVersion(o, meta.Version)
+	ds.setHighlights(o, meta.Highlights)
+	ds.setMetadata(o, meta)
+	return nil
+}
+
 func (ds *Datastore) setID(o interface{}, ID string) error {
 	eo := reflect.ValueOf(o).Elem()
 	if eo.Kind() != reflect.Struct {
 		return errors.Wrap(ErrInvalidType, `setID failed`)
 	}
-	idField := eo.FieldByName(ds.idFieldName)
+	idField := fieldByName(eo, ds.idFieldName, true)
 	if !idField.IsValid() || !idField.CanSet() || idField.Kind() != reflect.String {
 		return ErrInvalidIDField
 	}
@@ -692,13 +795,40 @@ func (ds *Datastore) getID(o interface{}) (string, error) {
 	if eo.Kind() != reflect.Struct {
 		return ``, errors.Wrap(ErrInvalidType, `getID failed`)
 	}
-	idField := eo.FieldByName(ds.idFieldName)
+	idField := fieldByName(eo, ds.idFieldName, false)
 	if !idField.IsValid() || idField.Kind() != reflect.String {
 		return ``, ErrInvalidIDField
 	}
 	return idField.String(), nil
 }
 
+// setVersion writes the document version into the field tagged with elasticorm:"version" - if there is one
+func (ds *Datastore) setVersion(o interface{}, version *int64) {
+	if ds.versionFieldName == `` || version == nil {
+		return
+	}
+	versionField := fieldByName(reflect.ValueOf(o).Elem(), ds.versionFieldName, true)
+	if versionField.IsValid() && versionField.CanSet() && isIntKind(versionField.Kind()) {
+		versionField.SetInt(*version)
+	}
+}
+
+// getVersion returns the document version of the field tagged with elasticorm:"version" - 0 if there is none
+func (ds *Datastore) getVersion(o interface{}) int64 {
+	if ds.versionFieldName == `` {
+		return 0
+	}
+	versionField := fieldByName(reflect.ValueOf(o).Elem(), ds.versionFieldName, false)
+	if !versionField.IsValid() || !isIntKind(versionField.Kind()) {
+		return 0
+	}
+	return versionField.Int()
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k == reflect.Int || k == reflect.Int32 || k == reflect.Int64
+}
+
 func (ds *Datastore) DecodeElasticResponse(source *json.RawMessage, ID string, o interface{}) error {
 	if source == nil {
 		return nil
@@ -3,13 +3,14 @@ package elasticorm_test
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
 
 	"gopkg.in/olivere/elastic.v5"
 )
@@ -98,6 +99,148 @@ func TestDatastoreUpdateAUser(t *testing.T) {
 	equals(t, `Post Firstname`, gotUser.FirstName)
 }
 
+func TestDatastoreUpdateWithVersionConflict(t *testing.T) {
+	type User struct {
+		ID        string `json:"id" elasticorm:"id"`
+		Version   int64  `json:"-" elasticorm:"version"`
+		FirstName string `json:"first_name"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	u := &User{FirstName: `Pre Firstname`}
+	err := ds.Create(u)
+	ok(t, err)
+	equals(t, int64(1), u.Version)
+
+	first := &User{}
+	err = ds.Find(u.ID, first)
+	ok(t, err)
+	second := &User{}
+	err = ds.Find(u.ID, second)
+	ok(t, err)
+
+	first.FirstName = `First Firstname`
+	err = ds.Update(first)
+	ok(t, err)
+	equals(t, int64(2), first.Version)
+
+	second.FirstName = `Second Firstname`
+	err = ds.Update(second)
+	equals(t, elasticorm.ErrConflict, err)
+
+	gotUser := User{}
+	err = ds.Find(u.ID, &gotUser)
+	ok(t, err)
+	equals(t, `First Firstname`, gotUser.FirstName)
+}
+
+func TestDatastoreUpsertAUser(t *testing.T) {
+	type User struct {
+		ID        string `json:"id" elasticorm:"id"`
+		FirstName string `json:"first_name"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	tests := []struct {
+		title           string
+		user            *User
+		expectedCreated bool
+	}{
+		{
+			title:           `Without an ID`,
+			user:            &User{FirstName: `Without ID`},
+			expectedCreated: true,
+		},
+		{
+			title:           `With an unknown ID`,
+			user:            &User{ID: `unknown-id`, FirstName: `Unknown ID`},
+			expectedCreated: true,
+		},
+		{
+			title:           `With an existing ID`,
+			user:            &User{ID: `unknown-id`, FirstName: `Existing ID`},
+			expectedCreated: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			created, err := ds.Upsert(tt.user)
+			ok(t, err)
+			equals(t, tt.expectedCreated, created)
+			assert(t, tt.user.ID != ``, `The ID of the user should be set after upserting`)
+
+			gotUser := User{}
+			err = ds.Find(tt.user.ID, &gotUser)
+			ok(t, err)
+			equals(t, *tt.user, gotUser)
+		})
+	}
+}
+
+func TestDatastoreDeleteAUser(t *testing.T) {
+	type User struct {
+		ID        string `json:"id" elasticorm:"id"`
+		FirstName string `json:"first_name"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	u := &User{FirstName: `Foobar`}
+	err := ds.Create(u)
+	ok(t, err)
+	ds.Refresh()
+
+	err = ds.Delete(u)
+	ok(t, err)
+
+	err = ds.Find(u.ID, &User{})
+	equals(t, elasticorm.ErrNotFound, err)
+
+	err = ds.DeleteByID(u.ID)
+	equals(t, elasticorm.ErrNotFound, err)
+}
+
+func TestDatastoreDeleteFiltered(t *testing.T) {
+	type User struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Name   string `json:"name" elasticorm:"sortable"`
+		Gender string `json:"gender" elasticorm:"type=keyword"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	err := ds.Create(&User{Name: `Unknown No. 1`, Gender: `female`})
+	ok(t, err)
+	err = ds.Create(&User{Name: `Unknown No. 2`, Gender: `male`})
+	ok(t, err)
+	err = ds.Create(&User{Name: `Unknown No. 3`, Gender: `female`})
+	ok(t, err)
+	ds.Refresh()
+
+	err = ds.DeleteFiltered(map[string]interface{}{`Gender`: `female`})
+	ok(t, err)
+	ds.Refresh()
+
+	found := []User{}
+	err = ds.FindAll(&found)
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Unknown No. 2`, found[0].Name)
+}
+
+func TestDatastoreDeleteFilteredWithoutFilters(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name"`
+	}
+	ds, err := elasticorm.NewDatastore(nil, elasticorm.ForStruct(&User{}))
+	ok(t, err)
+
+	err = ds.DeleteFiltered(nil)
+	equals(t, elasticorm.ErrNoFilters, err)
+	err = ds.DeleteFiltered(map[string]interface{}{})
+	equals(t, elasticorm.ErrNoFilters, err)
+}
+
 func TestDatastoreFindOneBy(t *testing.T) {
 	type User struct {
 		ID        string `json:"id" elasticorm:"id"`
@@ -271,6 +414,21 @@ func TestFindByGeoDistance(t *testing.T) {
 	equals(t, 2, len(found))
 	equals(t, "Juist", found[0].Name)
 	equals(t, "Memmert", found[1].Name)
+
+	found = []Isle{}
+	err = ds.FindByGeoDistance(
+		`Location`,
+		53.666499,
+		7.050261,
+		`11.1km`,
+		&found,
+		ds.Offset(1),
+		ds.Limit(1),
+	)
+	ok(t, err)
+
+	equals(t, 1, len(found))
+	equals(t, "Memmert", found[0].Name)
 }
 
 func TestFindAll(t *testing.T) {
@@ -436,6 +594,139 @@ func TestFilterFindAll(t *testing.T) {
 	}
 }
 
+func TestFilterRange(t *testing.T) {
+	type Product struct {
+		ID        string    `json:"id" elasticorm:"id"`
+		Name      string    `json:"name" elasticorm:"sortable"`
+		Price     float64   `json:"price"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	_, ds := initDatastore(t, &Product{})
+	day := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		err := ds.Create(&Product{
+			Name:      fmt.Sprintf("Product No. %d", i),
+			Price:     float64(i * 10),
+			CreatedAt: day.AddDate(0, 0, i),
+		})
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	tests := []struct {
+		title         string
+		filter        elasticorm.QueryOptFunc
+		foundNames    []string
+		expectedError error
+	}{
+		{
+			title:      `Price between 10 and 30`,
+			filter:     ds.FilterRange(`Price`, 10, 30),
+			foundNames: []string{`Product No. 1`, `Product No. 2`, `Product No. 3`},
+		},
+		{
+			title:      `Price greater or equal 30`,
+			filter:     ds.FilterRange(`Price`, 30.0, nil),
+			foundNames: []string{`Product No. 3`, `Product No. 4`},
+		},
+		{
+			title:      `Created until the second day`,
+			filter:     ds.FilterRange(`CreatedAt`, nil, day.AddDate(0, 0, 1)),
+			foundNames: []string{`Product No. 0`, `Product No. 1`},
+		},
+		{
+			title:         `String for a numeric field`,
+			filter:        ds.FilterRange(`Price`, `10`, nil),
+			expectedError: errors.New(`lower bound of Price: string can't be used for a range on a double field: invalid value for the mapped type of the field`),
+		},
+		{
+			title:         `Number for a date field`,
+			filter:        ds.FilterRange(`CreatedAt`, nil, 10),
+			expectedError: errors.New(`upper bound of CreatedAt: int can't be used for a range on a date field: invalid value for the mapped type of the field`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			found := []Product{}
+			err := ds.FindAll(&found, tt.filter, ds.SetSorting(`Name`, `asc`))
+			if tt.expectedError != nil {
+				equals(t, tt.expectedError.Error(), err.Error())
+				return
+			}
+			ok(t, err)
+			equals(t, len(tt.foundNames), len(found))
+			for n, name := range tt.foundNames {
+				equals(t, name, found[n].Name)
+			}
+		})
+	}
+}
+
+func TestStackedFilters(t *testing.T) {
+	type User struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Name   string `json:"name" elasticorm:"sortable"`
+		Gender string `json:"gender" elasticorm:"type=keyword"`
+		Age    int    `json:"age"`
+		Bio    string `json:"bio"`
+	}
+
+	_, ds := initDatastore(t, &User{})
+	users := []*User{
+		&User{Name: `Unknown No. 1`, Gender: `female`, Age: 20, Bio: `likes coffee`},
+		&User{Name: `Unknown No. 2`, Gender: `female`, Age: 30, Bio: `likes coffee`},
+		&User{Name: `Unknown No. 3`, Gender: `female`, Age: 40, Bio: `likes tea`},
+		&User{Name: `Unknown No. 4`, Gender: `male`, Age: 30, Bio: `likes coffee`},
+	}
+	for _, u := range users {
+		err := ds.Create(u)
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	found := []User{}
+	err := ds.FindAll(
+		&found,
+		ds.FilterByField(`Gender`, `female`),
+		ds.FilterRange(`Age`, 25, nil),
+		ds.MatchField(`Bio`, `coffee`),
+	)
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Unknown No. 2`, found[0].Name)
+
+	found = []User{}
+	err = ds.DoSearch(
+		elastic.NewMatchQuery(`bio`, `coffee`),
+		&found,
+		ds.FilterByField(`Gender`, `female`),
+		ds.FilterByField(`Age`, 20),
+	)
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Unknown No. 1`, found[0].Name)
+}
+
+func TestFilterOfAnotherDatastore(t *testing.T) {
+	type User struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Gender string `json:"gender" elasticorm:"type=keyword"`
+	}
+
+	client, ds := initDatastore(t, &User{})
+	other, err := elasticorm.NewDatastore(client, elasticorm.ForStruct(&User{}))
+	ok(t, err)
+
+	found := []User{}
+	err = ds.FindAll(&found, other.FilterByField(`Gender`, `female`))
+	equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+
+	err = ds.FindAll(&found, other.MatchField(`Gender`, `female`))
+	equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+}
+
 func initDatastore(t *testing.T, i interface{}) (*elastic.Client, *elasticorm.Datastore) {
 	client := elasticClient(t)
 	deleteAllIndices(t, client)
@@ -0,0 +1,113 @@
+package elasticorm
+
+import (
+	"reflect"
+	"strings"
+)
+
+// structFields returns the fields of the struct type the way encoding/json sees them. The fields of embedded structs
+// without a JSON name are promoted. A field hides the fields with the same name deeper down, and fields with the same
+// name on the same depth hide each other - unless exactly one of them has the name in its JSON tag.
+// Fields with the JSON tag "-" are returned as well, as they may hold the ID or other metadata.
+// The Index of the returned fields is the path from t
+func structFields(t reflect.Type) []reflect.StructField {
+	type candidate struct {
+		field  reflect.StructField
+		depth  int
+		tagged bool
+	}
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+
+	names := []string{}
+	candidates := map[string][]candidate{}
+	ignored := []reflect.StructField{}
+	visited := map[reflect.Type]bool{}
+	next := []embedded{{t: t}}
+	for depth := 0; len(next) > 0; depth++ {
+		current := next
+		next = nil
+		for _, e := range current {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for n := 0; n < e.t.NumField(); n++ {
+				f := e.t.Field(n)
+				f.Index = append(append([]int{}, e.index...), n)
+				ft := f.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				jsonName := strings.Split(f.Tag.Get(`json`), `,`)[0]
+				if f.Anonymous {
+					if f.PkgPath != `` && ft.Kind() != reflect.Struct {
+						continue
+					}
+					if jsonName == `` && ft.Kind() == reflect.Struct {
+						next = append(next, embedded{t: ft, index: f.Index})
+						continue
+					}
+				} else if f.PkgPath != `` {
+					continue
+				}
+				if f.Tag.Get(`json`) == `-` {
+					ignored = append(ignored, f)
+					continue
+				}
+				name := nameForField(f)
+				if _, ok := candidates[name]; !ok {
+					names = append(names, name)
+				}
+				candidates[name] = append(candidates[name], candidate{field: f, depth: depth, tagged: jsonName != ``})
+			}
+		}
+	}
+
+	fields := []reflect.StructField{}
+	for _, name := range names {
+		dominant := []candidate{}
+		for _, c := range candidates[name] {
+			if c.depth == candidates[name][0].depth {
+				dominant = append(dominant, c)
+			}
+		}
+		if len(dominant) > 1 {
+			tagged := []candidate{}
+			for _, c := range dominant {
+				if c.tagged {
+					tagged = append(tagged, c)
+				}
+			}
+			dominant = tagged
+		}
+		if len(dominant) == 1 {
+			fields = append(fields, dominant[0].field)
+		}
+	}
+	return append(fields, ignored...)
+}
+
+// fieldByName returns the (possibly promoted) field of the struct value. Nil pointers to embedded structs on the way
+// are allocated, when alloc is set - otherwise the returned value is invalid
+func fieldByName(v reflect.Value, name string, alloc bool) reflect.Value {
+	f, ok := v.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}
+	}
+	for i, n := range f.Index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(n)
+	}
+	return v
+}
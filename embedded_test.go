@@ -0,0 +1,48 @@
+package elasticorm_test
+
+import (
+	"testing"
+)
+
+func TestEmbeddedStructs(t *testing.T) {
+	type Entity struct {
+		ID      string `json:"id" elasticorm:"id"`
+		Version int64  `json:"-" elasticorm:"version"`
+	}
+	type PostalAddress struct {
+		Street string `json:"street" elasticorm:"sortable"`
+		City   string `json:"city" elasticorm:"type=keyword"`
+	}
+	type Customer struct {
+		Entity
+		*PostalAddress
+		Name string `json:"name"`
+	}
+
+	_, ds := initDatastore(t, &Customer{})
+	c := Customer{Name: `Frederic`, PostalAddress: &PostalAddress{Street: `Hauptstraße`, City: `Berlin`}}
+	err := ds.Create(&c)
+	ok(t, err)
+	assert(t, c.ID != ``, `expected the promoted ID to be set`)
+	equals(t, int64(1), c.Version)
+	err = ds.Create(&Customer{Name: `Gopher`, PostalAddress: &PostalAddress{Street: `Am Bahnhof`, City: `Hamburg`}})
+	ok(t, err)
+	ds.Refresh()
+
+	found := Customer{}
+	err = ds.Find(c.ID, &found)
+	ok(t, err)
+	equals(t, c, found)
+
+	found = Customer{}
+	err = ds.FindOneBy(`City`, `Berlin`, &found)
+	ok(t, err)
+	equals(t, `Frederic`, found.Name)
+
+	all := []Customer{}
+	err = ds.FindAll(&all, ds.SetSorting(`Street`, `asc`))
+	ok(t, err)
+	equals(t, 2, len(all))
+	equals(t, `Gopher`, all[0].Name)
+	equals(t, `Frederic`, all[1].Name)
+}
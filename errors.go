@@ -12,12 +12,48 @@ var (
 	// ErrInvalidResultType is returned when no pointer is passed to a find method
 	ErrInvalidResultType = errors.New(`invalid result type`)
 
+	// ErrInvalidCursor is returned when a cursor passed to After can't be decoded
+	ErrInvalidCursor = errors.New(`invalid cursor`)
+
+	// ErrInvalidQueryString is returned by ParseQuery, when the query string has a syntax error
+	ErrInvalidQueryString = errors.New(`invalid query string`)
+
 	// ErrInvalidIDField is returned when the defined ID field can't be set
 	ErrInvalidIDField = errors.New(`invalid ID field`)
 
+	// ErrInvalidValue is returned when a value passed to a query doesn't match the mapped type of the field
+	ErrInvalidValue = errors.New(`invalid value for the mapped type of the field`)
+
+	// ErrNoAutocompleteField is returned by Autocomplete, when the field isn't tagged with elasticorm:"autocomplete"
+	ErrNoAutocompleteField = errors.New(`field is not mapped for autocompletion`)
+
+	// ErrNoSuggestField is returned by Suggest, when the field isn't tagged with elasticorm:"suggest"
+	ErrNoSuggestField = errors.New(`field is not mapped for suggestions`)
+
+	// ErrNotSortable is returned by SetSorting, when the field is a text field without a keyword sub field (e.g. by the sortable tag)
+	ErrNotSortable = errors.New(`field is not sortable`)
+
+	// ErrNoFilters is returned by DeleteFiltered, when no filters are passed, which would delete all documents
+	ErrNoFilters = errors.New(`no filters given`)
+
+	// ErrNoSearchableFields is returned by FullTextSearch, when no field of the struct is tagged with elasticorm:"searchable"
+	ErrNoSearchableFields = errors.New(`no searchable fields`)
+
+	// ErrBulkIndexerClosed is returned when an item is added to a BulkIndexer, which has already been closed
+	ErrBulkIndexerClosed = errors.New(`bulk indexer is closed`)
+
+	// ErrDecodeWithoutNext is returned by Iterator.Decode, when it is called without a preceding successful call of Next
+	ErrDecodeWithoutNext = errors.New(`Decode called without Next`)
+
+	// ErrNoStruct is returned by methods, which need the struct of the datastore, when it has been created without ForStruct
+	ErrNoStruct = errors.New(`no struct registered for this datastore`)
+
 	// ErrNotFound is returned when no record could be found
 	ErrNotFound = errors.New(`not found`)
 
+	// ErrConflict is returned by Update, when the version of the passed in struct doesn't match the version of the stored document
+	ErrConflict = errors.New(`version conflict`)
+
 	// ErrCreationFailed is returned by the Create method, when there was no error by the elastic client, but the record could not have been created - TODO when does this happen?
 	ErrCreationFailed = errors.New(`creation of new elasticsearch record failed`)
 
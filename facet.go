@@ -0,0 +1,119 @@
+package elasticorm
+
+import (
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// facetValuesAggregation is the name of the terms aggregation inside of the filter aggregation of each facet
+const facetValuesAggregation = `values`
+
+// defaultFacetSize is the maximum number of values per facet, when FacetSize isn't used. The elasticsearch default of 10 is too low for most facets
+const defaultFacetSize = 100
+
+// FacetValue is a value of a facet with the number of matching documents
+type FacetValue struct {
+	Value interface{}
+	Count int64
+}
+
+// Facets maps the struct field names of the facets to their values
+type Facets map[string][]FacetValue
+
+// FacetedSearch executes the query and returns the counts of the values of the facets along with the hits in results.
+// The facets are struct field names - without facets the fields tagged with elasticorm:"facet" are used.
+// The selections (by struct field name) restrict the hits, but the counts of a facet ignore its own selection,
+// so that the counts of the alternative values of a facet stay visible. The values with the most matching documents are returned first,
+// up to 100 per facet or the number set by FacetSize
+func (ds *Datastore) FacetedSearch(query Query, facets []string, selections map[string][]interface{}, results interface{}, opts ...QueryOptFunc) (Facets, error) {
+	if len(facets) == 0 {
+		facets = ds.facetFieldNames
+	}
+	q, err := query.elasticQuery(ds.resolveFieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	selectionFilters := make(map[string]elastic.Query, len(selections))
+	for fieldName, values := range selections {
+		if len(values) == 0 {
+			continue
+		}
+		elasticFieldName, err := ds.resolveFieldName(fieldName)
+		if err != nil {
+			return nil, errors.Wrapf(err, `selection %s`, fieldName)
+		}
+		selectionFilters[fieldName] = elastic.NewTermsQuery(elasticFieldName, values...)
+	}
+
+	opts = append(append([]QueryOptFunc{}, opts...), func(srv *elastic.SearchService) error {
+		srv.PostFilter(filterAllBut(selectionFilters, ``))
+		size := defaultFacetSize
+		ds.searches.update(srv, func(sq *searchQuery) {
+			if sq.facetSize > 0 {
+				size = sq.facetSize
+			}
+		})
+		for _, facet := range facets {
+			elasticFieldName, err := ds.resolveFieldName(facet)
+			if err != nil {
+				return errors.Wrapf(err, `facet %s`, facet)
+			}
+			srv.Aggregation(facet, elastic.NewFilterAggregation().
+				Filter(filterAllBut(selectionFilters, facet)).
+				SubAggregation(facetValuesAggregation, elastic.NewTermsAggregation().Field(elasticFieldName).Size(size)),
+			)
+		}
+		return nil
+	})
+
+	res, _, err := ds.executeSearch(q, opts)
+	if err != nil {
+		return nil, err
+	}
+	err = ds.DecodeElasticResponses(hitsToResults(res.Hits.Hits), results)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(Facets, len(facets))
+	for _, facet := range facets {
+		filtered, ok := res.Aggregations.Filter(facet)
+		if !ok {
+			return nil, errors.Errorf("facet %s is missing in the response", facet)
+		}
+		values, ok := filtered.Terms(facetValuesAggregation)
+		if !ok {
+			return nil, errors.Errorf("values of facet %s are missing in the response", facet)
+		}
+		counts[facet] = make([]FacetValue, len(values.Buckets))
+		for n, bucket := range values.Buckets {
+			counts[facet][n] = FacetValue{Value: bucket.Key, Count: bucket.DocCount}
+		}
+	}
+	return counts, nil
+}
+
+// FacetSize sets the maximum number of values per facet of a FacetedSearch
+func (ds *Datastore) FacetSize(size int) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		if size < 1 {
+			return errors.Wrapf(ErrInvalidOption, "facet size must be at least 1, not %d", size)
+		}
+		ds.searches.update(srv, func(sq *searchQuery) {
+			sq.facetSize = size
+		})
+		return nil
+	}
+}
+
+// filterAllBut returns a query matching all filters except the one of the excluded facet
+func filterAllBut(filters map[string]elastic.Query, excluded string) elastic.Query {
+	q := elastic.NewBoolQuery()
+	for facet, filter := range filters {
+		if facet != excluded {
+			q = q.Filter(filter)
+		}
+	}
+	return q
+}
@@ -0,0 +1,90 @@
+package elasticorm_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestFacetedSearch(t *testing.T) {
+	type Product struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Name  string `json:"name" elasticorm:"sortable"`
+		Color string `json:"color" elasticorm:"type=keyword,facet"`
+		Size  string `json:"size" elasticorm:"type=keyword,facet"`
+		Brand string `json:"brand" elasticorm:"type=keyword"`
+	}
+	_, ds := initDatastore(t, &Product{})
+
+	products := []*Product{
+		&Product{Name: `Product No. 1`, Color: `red`, Size: `S`, Brand: `acme`},
+		&Product{Name: `Product No. 2`, Color: `red`, Size: `M`, Brand: `acme`},
+		&Product{Name: `Product No. 3`, Color: `blue`, Size: `M`, Brand: `acme`},
+		&Product{Name: `Product No. 4`, Color: `blue`, Size: `L`, Brand: `other`},
+	}
+	res, err := ds.BulkCreate(products)
+	ok(t, err)
+	assert(t, !res.HasErrors(), `BulkCreate should not fail: %#v`, res.Failed)
+	ds.Refresh()
+
+	found := []Product{}
+	facets, err := ds.FacetedSearch(
+		elasticorm.Query{},
+		nil,
+		map[string][]interface{}{`Color`: []interface{}{`red`}},
+		&found,
+		ds.SetSorting(`Name`, `asc`),
+	)
+	ok(t, err)
+
+	equals(t, 2, len(found))
+	equals(t, `Product No. 1`, found[0].Name)
+	equals(t, `Product No. 2`, found[1].Name)
+	// the color counts ignore the color selection
+	equals(t, []elasticorm.FacetValue{{Value: `blue`, Count: 2}, {Value: `red`, Count: 2}}, facets[`Color`])
+	equals(t, []elasticorm.FacetValue{{Value: `M`, Count: 1}, {Value: `S`, Count: 1}}, facets[`Size`])
+
+	found = []Product{}
+	facets, err = ds.FacetedSearch(
+		elasticorm.Term(`Brand`, `acme`),
+		[]string{`Size`},
+		map[string][]interface{}{`Size`: []interface{}{`M`}},
+		&found,
+	)
+	ok(t, err)
+	equals(t, 2, len(found))
+	equals(t, 1, len(facets))
+	equals(t, []elasticorm.FacetValue{{Value: `M`, Count: 2}, {Value: `S`, Count: 1}}, facets[`Size`])
+}
+
+func TestFacetedSearchSize(t *testing.T) {
+	type Product struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Color string `json:"color" elasticorm:"type=keyword,facet"`
+	}
+	_, ds := initDatastore(t, &Product{})
+
+	// more colors than the elasticsearch default of 10 buckets
+	products := []*Product{}
+	for i := 0; i < 12; i++ {
+		products = append(products, &Product{Color: fmt.Sprintf("color %02d", i)})
+	}
+	res, err := ds.BulkCreate(products)
+	ok(t, err)
+	assert(t, !res.HasErrors(), `BulkCreate should not fail: %#v`, res.Failed)
+	ds.Refresh()
+
+	found := []Product{}
+	facets, err := ds.FacetedSearch(elasticorm.Query{}, nil, nil, &found)
+	ok(t, err)
+	equals(t, 12, len(facets[`Color`]))
+
+	facets, err = ds.FacetedSearch(elasticorm.Query{}, nil, nil, &found, ds.FacetSize(5))
+	ok(t, err)
+	equals(t, 5, len(facets[`Color`]))
+
+	_, err = ds.FacetedSearch(elasticorm.Query{}, nil, nil, &found, ds.FacetSize(0))
+	equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+}
@@ -0,0 +1,114 @@
+package elasticorm
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// searchableField is a field tagged with elasticorm:"searchable" and its optional boost
+type searchableField struct {
+	name  string
+	boost float64 // 0 when no boost is set
+}
+
+// searchableFieldsForType returns the fields of the struct (pointer) type, which are tagged with elasticorm:"searchable"
+func searchableFieldsForType(t reflect.Type) ([]searchableField, error) {
+	names := fieldNamesWithOption(t, `searchable`)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fields := make([]searchableField, len(names))
+	for n, name := range names {
+		fields[n].name = name
+		f, _ := t.FieldByName(name)
+		if value, ok := optionValueForField(f, `boost`); ok {
+			boost, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, errors.Wrapf(ErrInvalidOption, "boost %s of %s must be a number", value, name)
+			}
+			fields[n].boost = boost
+		}
+	}
+	return fields, nil
+}
+
+// FullTextSearch decodes the documents matching the text in one of the fields tagged with elasticorm:"searchable" into results.
+// A match in a field tagged with boost=N weighs N times more. Unless the opts sort otherwise the best matches come first.
+// The search can be tuned with the opts Fuzziness, Operator and MinimumShouldMatch
+func (ds *Datastore) FullTextSearch(text string, results interface{}, opts ...QueryOptFunc) error {
+	if len(ds.searchableFields) == 0 {
+		return ErrNoSearchableFields
+	}
+	q := elastic.NewMultiMatchQuery(text)
+	for _, f := range ds.searchableFields {
+		elasticFieldName, err := ds.resolveFieldName(f.name)
+		if err != nil {
+			return err
+		}
+		if f.boost != 0 {
+			q = q.FieldWithBoost(elasticFieldName, f.boost)
+		} else {
+			q = q.Field(elasticFieldName)
+		}
+	}
+	opts = append([]QueryOptFunc{ds.withFullText(q)}, opts...)
+	return ds.FindQuery(results, q, opts...)
+}
+
+// withFullText registers the full text query of the search for the full text options
+func (ds *Datastore) withFullText(q *elastic.MultiMatchQuery) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		ds.searches.update(srv, func(sq *searchQuery) {
+			sq.fullText = q
+		})
+		return nil
+	}
+}
+
+// updateFullText calls fn with the query of the full text search. It fails for all other searches
+func (ds *Datastore) updateFullText(srv *elastic.SearchService, option string, fn func(*elastic.MultiMatchQuery)) error {
+	isFullText := false
+	ds.searches.update(srv, func(sq *searchQuery) {
+		if sq.fullText != nil {
+			fn(sq.fullText)
+			isFullText = true
+		}
+	})
+	if !isFullText {
+		return errors.Wrapf(ErrInvalidOption, "%s can only be used with FullTextSearch", option)
+	}
+	return nil
+}
+
+// Fuzziness allows matches with up to the edit distance (e.g. 1, 2 or AUTO) in a full text search
+func (ds *Datastore) Fuzziness(fuzziness string) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		return ds.updateFullText(srv, `fuzziness`, func(q *elastic.MultiMatchQuery) {
+			q.Fuzziness(fuzziness)
+		})
+	}
+}
+
+// Operator sets wether all (and) or any (or) of the words of a full text search must match
+func (ds *Datastore) Operator(operator string) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		if operator != `and` && operator != `or` {
+			return errors.Wrapf(ErrInvalidOption, "operator must be and or or, not %s", operator)
+		}
+		return ds.updateFullText(srv, `operator`, func(q *elastic.MultiMatchQuery) {
+			q.Operator(operator)
+		})
+	}
+}
+
+// MinimumShouldMatch sets how many of the words of a full text search must match - e.g. 2 or 75%
+func (ds *Datastore) MinimumShouldMatch(minimum string) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		return ds.updateFullText(srv, `minimum_should_match`, func(q *elastic.MultiMatchQuery) {
+			q.MinimumShouldMatch(minimum)
+		})
+	}
+}
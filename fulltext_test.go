@@ -0,0 +1,72 @@
+package elasticorm_test
+
+import (
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestFullTextSearch(t *testing.T) {
+	type Article struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Title string `json:"title" elasticorm:"searchable,sortable,boost=3"`
+		Body  string `json:"body" elasticorm:"searchable"`
+		Tag   string `json:"tag" elasticorm:"type=keyword"`
+	}
+
+	_, ds := initDatastore(t, &Article{})
+	articles := []Article{
+		{Title: `Elasticsearch for gophers`, Body: `How to search with go`, Tag: `go`},
+		{Title: `Gardening`, Body: `Elasticsearch doesn't help with the tomatoes`, Tag: `garden`},
+		{Title: `Cooking`, Body: `Tomatoes and basil`, Tag: `food`},
+	}
+	for _, a := range articles {
+		err := ds.Create(&a)
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	tests := []struct {
+		title    string
+		text     string
+		opts     []elasticorm.QueryOptFunc
+		expected []string
+	}{
+		// the match in the boosted title ranks first
+		{title: `boosted`, text: `elasticsearch`, expected: []string{`Elasticsearch for gophers`, `Gardening`}},
+		{title: `filtered`, text: `elasticsearch`, opts: []elasticorm.QueryOptFunc{ds.FilterByField(`Tag`, `garden`)}, expected: []string{`Gardening`}},
+		{title: `fuzziness`, text: `tomatos`, opts: []elasticorm.QueryOptFunc{ds.Fuzziness(`AUTO`), ds.SetSorting(`Title`, `asc`)}, expected: []string{`Cooking`, `Gardening`}},
+		{title: `operator`, text: `tomatoes basil`, opts: []elasticorm.QueryOptFunc{ds.Operator(`and`)}, expected: []string{`Cooking`}},
+		{title: `minimum should match`, text: `tomatoes basil garden`, opts: []elasticorm.QueryOptFunc{ds.MinimumShouldMatch(`2`)}, expected: []string{`Cooking`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			found := []Article{}
+			err := ds.FullTextSearch(tt.text, &found, tt.opts...)
+			ok(t, err)
+			titles := []string{}
+			for _, a := range found {
+				titles = append(titles, a.Title)
+			}
+			equals(t, tt.expected, titles)
+		})
+	}
+
+	found := []Article{}
+	err := ds.FindAll(&found, ds.Fuzziness(`AUTO`))
+	equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+}
+
+func TestFullTextSearchWithoutSearchableFields(t *testing.T) {
+	type Article struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Title string `json:"title"`
+	}
+	ds, err := elasticorm.NewDatastore(nil, elasticorm.ForStruct(&Article{}))
+	ok(t, err)
+
+	found := []Article{}
+	err = ds.FullTextSearch(`elasticsearch`, &found)
+	equals(t, elasticorm.ErrNoSearchableFields, err)
+}
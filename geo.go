@@ -0,0 +1,120 @@
+package elasticorm
+
+import (
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// GeoPoint is a location, which is mapped as geo_point
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// GeoShape is a GeoJSON geometry, which is mapped as geo_shape. It is built with NewPointShape, NewEnvelopeShape or NewPolygonShape
+type GeoShape struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// NewPointShape returns a GeoShape of a single point
+func NewPointShape(p GeoPoint) GeoShape {
+	return GeoShape{Type: `point`, Coordinates: geoJSONPosition(p)}
+}
+
+// NewEnvelopeShape returns a GeoShape of the rectangle between the top left and the bottom right corner
+func NewEnvelopeShape(topLeft, bottomRight GeoPoint) GeoShape {
+	return GeoShape{Type: `envelope`, Coordinates: [][]float64{geoJSONPosition(topLeft), geoJSONPosition(bottomRight)}}
+}
+
+// NewPolygonShape returns a GeoShape of the polygon with the outer ring and optional holes. The rings are closed
+// automatically, when the last point doesn't equal the first one
+func NewPolygonShape(outer []GeoPoint, holes ...[]GeoPoint) GeoShape {
+	rings := make([][][]float64, 0, len(holes)+1)
+	for _, ring := range append([][]GeoPoint{outer}, holes...) {
+		positions := make([][]float64, 0, len(ring)+1)
+		for _, p := range ring {
+			positions = append(positions, geoJSONPosition(p))
+		}
+		if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+			positions = append(positions, geoJSONPosition(ring[0]))
+		}
+		rings = append(rings, positions)
+	}
+	return GeoShape{Type: `polygon`, Coordinates: rings}
+}
+
+// geoJSONPosition returns the point in GeoJSON order - longitude first
+func geoJSONPosition(p GeoPoint) []float64 {
+	return []float64{p.Lon, p.Lat}
+}
+
+// GeoShapeRelation is the spatial relation between the shapes of the documents and the shape of a FindByGeoShape query
+type GeoShapeRelation string
+
+const (
+	// GeoShapeIntersects finds the documents with a shape intersecting the query shape
+	GeoShapeIntersects GeoShapeRelation = `intersects`
+	// GeoShapeWithin finds the documents with a shape within the query shape
+	GeoShapeWithin GeoShapeRelation = `within`
+)
+
+// FindByGeoPolygon finds the documents with a geo_point in the field inside of the polygon.
+// The field must be mapped as geo_point - e.g. with the Go type GeoPoint
+func (ds *Datastore) FindByGeoPolygon(fieldName string, points []GeoPoint, results interface{}, opts ...QueryOptFunc) error {
+	elasticFieldName, cfg, err := ds.IndexDefinition.elasticField(ds.typeName, fieldName)
+	if err != nil {
+		return err
+	}
+	if cfg.Type != `geo_point` {
+		return errors.Wrapf(ErrInvalidValue, `%s is mapped as %s, not as geo_point`, fieldName, cfg.Type)
+	}
+	query := elastic.NewGeoPolygonQuery(elasticFieldName)
+	for _, p := range points {
+		query = query.AddPoint(p.Lat, p.Lon)
+	}
+	return ds.FindQuery(results, elastic.NewBoolQuery().Filter(query), opts...)
+}
+
+// FindByGeoShape finds the documents with a shape in the field, which is in the relation to the shape.
+// The field must be mapped as geo_shape - e.g. with the tag elasticorm:"geo_shape" or the Go type GeoShape
+func (ds *Datastore) FindByGeoShape(fieldName string, shape GeoShape, relation GeoShapeRelation, results interface{}, opts ...QueryOptFunc) error {
+	elasticFieldName, cfg, err := ds.IndexDefinition.elasticField(ds.typeName, fieldName)
+	if err != nil {
+		return err
+	}
+	if cfg.Type != `geo_shape` {
+		return errors.Wrapf(ErrInvalidValue, `%s is mapped as %s, not as geo_shape`, fieldName, cfg.Type)
+	}
+	query := geoShapeQuery{field: elasticFieldName, shape: shape, relation: relation}
+	return ds.FindQuery(results, elastic.NewBoolQuery().Filter(query), opts...)
+}
+
+// geoShapeQuery is a geo_shape query with an inline shape
+type geoShapeQuery struct {
+	field    string
+	shape    GeoShape
+	relation GeoShapeRelation
+}
+
+func (q geoShapeQuery) Source() (interface{}, error) {
+	return map[string]interface{}{
+		`geo_shape`: map[string]interface{}{
+			q.field: map[string]interface{}{
+				`shape`:    q.shape,
+				`relation`: q.relation,
+			},
+		},
+	}, nil
+}
+
+// sortByGeoDistance sorts the hits by their distance to the point - in front of all other sortings
+func (ds *Datastore) sortByGeoDistance(elasticFieldName string, lat, lon float64) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		srv.SortBy(elastic.NewGeoDistanceSort(elasticFieldName).Point(lat, lon))
+		ds.searches.update(srv, func(sq *searchQuery) {
+			sq.sorted = true
+		})
+		return nil
+	}
+}
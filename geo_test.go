@@ -0,0 +1,87 @@
+package elasticorm_test
+
+import (
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestFindByGeoPolygon(t *testing.T) {
+	type Isle struct {
+		ID       string              `json:"id" elasticorm:"id"`
+		Name     string              `json:"name" elasticorm:"type=keyword"`
+		Location elasticorm.GeoPoint `json:"loc"`
+	}
+
+	_, ds := initDatastore(t, &Isle{})
+	isles := []Isle{
+		{Name: `Memmert`, Location: elasticorm.GeoPoint{Lat: 53.640652, Lon: 6.887995}},
+		{Name: `Juist`, Location: elasticorm.GeoPoint{Lat: 53.681747, Lon: 7.008158}},
+		{Name: `Langeoog`, Location: elasticorm.GeoPoint{Lat: 53.743725, Lon: 7.481725}},
+	}
+	for _, isle := range isles {
+		err := ds.Create(&isle)
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	polygon := []elasticorm.GeoPoint{
+		{Lat: 53.60, Lon: 6.80},
+		{Lat: 53.75, Lon: 6.80},
+		{Lat: 53.75, Lon: 7.10},
+		{Lat: 53.60, Lon: 7.10},
+	}
+	found := []Isle{}
+	err := ds.FindByGeoPolygon(`Location`, polygon, &found, ds.FilterByField(`Name`, `Juist`))
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Juist`, found[0].Name)
+
+	found = []Isle{}
+	err = ds.FindByGeoPolygon(`Location`, polygon, &found)
+	ok(t, err)
+	equals(t, 2, len(found))
+
+	err = ds.FindByGeoPolygon(`Name`, polygon, &found)
+	equals(t, elasticorm.ErrInvalidValue, errors.Cause(err))
+}
+
+func TestFindByGeoShape(t *testing.T) {
+	type Isle struct {
+		ID   string              `json:"id" elasticorm:"id"`
+		Name string              `json:"name"`
+		Area elasticorm.GeoShape `json:"area"`
+	}
+
+	_, ds := initDatastore(t, &Isle{})
+	isles := []Isle{
+		{Name: `Juist`, Area: elasticorm.NewEnvelopeShape(elasticorm.GeoPoint{Lat: 53.70, Lon: 6.85}, elasticorm.GeoPoint{Lat: 53.66, Lon: 7.10})},
+		{Name: `Langeoog`, Area: elasticorm.NewEnvelopeShape(elasticorm.GeoPoint{Lat: 53.76, Lon: 7.40}, elasticorm.GeoPoint{Lat: 53.72, Lon: 7.60})},
+	}
+	for _, isle := range isles {
+		err := ds.Create(&isle)
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	found := []Isle{}
+	err := ds.FindByGeoShape(`Area`, elasticorm.NewPointShape(elasticorm.GeoPoint{Lat: 53.68, Lon: 7.0}), elasticorm.GeoShapeIntersects, &found)
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Juist`, found[0].Name)
+
+	eastFrisia := elasticorm.NewPolygonShape([]elasticorm.GeoPoint{
+		{Lat: 53.50, Lon: 6.50},
+		{Lat: 53.90, Lon: 6.50},
+		{Lat: 53.90, Lon: 8.00},
+		{Lat: 53.50, Lon: 8.00},
+	})
+	found = []Isle{}
+	err = ds.FindByGeoShape(`Area`, eastFrisia, elasticorm.GeoShapeWithin, &found)
+	ok(t, err)
+	equals(t, 2, len(found))
+
+	err = ds.FindByGeoShape(`Name`, eastFrisia, elasticorm.GeoShapeWithin, &found)
+	equals(t, elasticorm.ErrInvalidValue, errors.Cause(err))
+}
@@ -0,0 +1,44 @@
+package elasticorm
+
+import (
+	"reflect"
+
+	"github.com/olivere/elastic"
+)
+
+// Highlight requests highlighted snippets of the matches in the fields. The snippets are decoded into the struct field
+// tagged with elasticorm:"highlights", which must be a map[string][]string. Its keys are the struct field names
+func (ds *Datastore) Highlight(fieldNames ...string) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		fields := make([]*elastic.HighlighterField, len(fieldNames))
+		for n, fieldName := range fieldNames {
+			elasticFieldName, err := ds.resolveFieldName(fieldName)
+			if err != nil {
+				return err
+			}
+			fields[n] = elastic.NewHighlighterField(elasticFieldName)
+		}
+		srv.Highlight(elastic.NewHighlight().Fields(fields...))
+		return nil
+	}
+}
+
+// setHighlights writes the highlights into the field tagged with elasticorm:"highlights" - if there is one
+func (ds *Datastore) setHighlights(o interface{}, highlights map[string][]string) {
+	if ds.highlightsFieldName == `` || len(highlights) == 0 {
+		return
+	}
+	highlightsField := fieldByName(reflect.ValueOf(o).Elem(), ds.highlightsFieldName, true)
+	if !highlightsField.IsValid() || !highlightsField.CanSet() || highlightsField.Type() != reflect.TypeOf(map[string][]string{}) {
+		return
+	}
+	byStructField := make(map[string][]string, len(highlights))
+	for elasticFieldName, snippets := range highlights {
+		fieldName, err := ds.IndexDefinition.structFieldName(ds.typeName, elasticFieldName)
+		if err != nil {
+			fieldName = elasticFieldName
+		}
+		byStructField[fieldName] = snippets
+	}
+	highlightsField.Set(reflect.ValueOf(byStructField))
+}
@@ -0,0 +1,29 @@
+package elasticorm_test
+
+import (
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+)
+
+func TestHighlight(t *testing.T) {
+	type User struct {
+		ID         string              `json:"id" elasticorm:"id"`
+		Name       string              `json:"name"`
+		Bio        string              `json:"bio"`
+		Highlights map[string][]string `json:"-" elasticorm:"highlights"`
+	}
+
+	_, ds := initDatastore(t, &User{})
+	err := ds.Create(&User{Name: `Frederic`, Bio: `likes go and elasticsearch`})
+	ok(t, err)
+	err = ds.Create(&User{Name: `Gopher`, Bio: `digs holes`})
+	ok(t, err)
+	ds.Refresh()
+
+	found := []User{}
+	err = ds.Search(elasticorm.Match(`Bio`, `elasticsearch`), &found, ds.Highlight(`Bio`))
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, map[string][]string{`Bio`: []string{`likes go and <em>elasticsearch</em>`}}, found[0].Highlights)
+}
@@ -3,6 +3,7 @@ package elasticorm
 import (
 	"errors"
 	"fmt"
+	"reflect"
 )
 
 // IndexDefinition is a struct which marshals to a valid JSON configuration for creating a new elasticsearch index
@@ -19,6 +20,22 @@ func (def IndexDefinition) elasticFieldName(typeName string, fieldName string) (
 	return typeMapping.elasticFieldName(fieldName)
 }
 
+func (def IndexDefinition) elasticField(typeName string, fieldName string) (string, MappingFieldConfig, error) {
+	typeMapping, ok := def.Mappings[typeName]
+	if !ok {
+		return ``, MappingFieldConfig{}, errors.New(`No mapping for this type in this index definition`)
+	}
+	return typeMapping.elasticField(fieldName)
+}
+
+func (def IndexDefinition) structFieldName(typeName string, elasticFieldName string) (string, error) {
+	typeMapping, ok := def.Mappings[typeName]
+	if !ok {
+		return ``, errors.New(`No mapping for this type in this index definition`)
+	}
+	return typeMapping.structFieldName(elasticFieldName)
+}
+
 type IndexSettings struct {
 	NumberOfShards   int            `json:"number_of_shards,omitempty"`
 	NumberOfReplicas int            `json:"number_of_replicas,omitempty"`
@@ -80,23 +97,62 @@ func NewIndexDefinition(options ...IndexDefinitionFunc) (IndexDefinition, error)
 	}
 	for _, m := range def.Mappings {
 		for _, analyzer := range m.Analyzers() {
-			if analyzer == "case_insensitive_ref_id" {
-				if def.Settings.Analysis == nil {
-					def.Settings.Analysis = &IndexAnalysis{
-						Analyzer: map[string]Analyzer{},
-					}
-				}
-				def.Settings.Analysis.Analyzer[analyzer] = Analyzer{
-					Type:      "custom",
-					Tokenizer: "keyword",
-					Filter:    []string{"lowercase"},
-				}
-			}
+			def.addBuiltinAnalyzer(analyzer)
 		}
 	}
 	return def, nil
 }
 
+// builtinAnalyzers are the analyzers elasticorm tags rely on. They are added to the index definition when a mapping uses them
+var builtinAnalyzers = map[string]Analyzer{
+	"case_insensitive_ref_id": {
+		Type:      "custom",
+		Tokenizer: "keyword",
+		Filter:    []string{"lowercase"},
+	},
+	autocompleteAnalyzer: {
+		Type:      "custom",
+		Tokenizer: autocompleteTokenizer,
+		Filter:    []string{"lowercase"},
+	},
+	autocompleteSearchAnalyzer: {
+		Type:      "custom",
+		Tokenizer: "standard",
+		Filter:    []string{"lowercase"},
+	},
+}
+
+// builtinTokenizers are the tokenizers the builtin analyzers rely on
+var builtinTokenizers = map[string]Tokenizer{
+	autocompleteTokenizer: {
+		Type:       "edge_ngram",
+		MinGram:    1,
+		MaxGram:    20,
+		TokenChars: []string{"letter", "digit"},
+	},
+}
+
+// addBuiltinAnalyzer adds the analyzer and its tokenizer, when it is a builtin one
+func (d *IndexDefinition) addBuiltinAnalyzer(name string) {
+	analyzer, ok := builtinAnalyzers[name]
+	if !ok {
+		return
+	}
+	if d.Settings.Analysis == nil {
+		d.Settings.Analysis = &IndexAnalysis{}
+	}
+	if d.Settings.Analysis.Analyzer == nil {
+		d.Settings.Analysis.Analyzer = map[string]Analyzer{}
+	}
+	d.Settings.Analysis.Analyzer[name] = analyzer
+	if tokenizer, ok := builtinTokenizers[analyzer.Tokenizer]; ok {
+		if d.Settings.Analysis.Tokenizer == nil {
+			d.Settings.Analysis.Tokenizer = map[string]Tokenizer{}
+		}
+		d.Settings.Analysis.Tokenizer[analyzer.Tokenizer] = tokenizer
+	}
+}
+
 // SetNumberOfShards is a IndexDefinitionFunc which can be passed to NewIndexDefinition and sets the number_of_shards setting
 func SetNumberOfShards(number int) IndexDefinitionFunc {
 	return func(def *IndexDefinition) error {
@@ -122,6 +178,6 @@ func AddMappingFromStruct(name string, i interface{}) IndexDefinitionFunc {
 			return err
 		}
 		def.Mappings[name] = mapping
-		return nil
+		return def.addAnalysis(analysisForType(reflect.TypeOf(i)))
 	}
 }
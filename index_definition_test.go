@@ -66,6 +66,37 @@ func TestIndexDefinition(t *testing.T) {
 			},
 			expectedJSON: `{"settings":{"number_of_replicas":2,"analysis":{"analyzer":{"case_insensitive_ref_id":{"type":"custom","tokenizer":"keyword","filter":["lowercase"]}}}},"mappings":{"customer":{"properties":{"email":{"type":"text","analyzer":"case_insensitive_ref_id"},"first_name":{"type":"text"}}}}}`,
 		},
+		{
+			title: `Index definition with a customer mapping with an autocomplete field`,
+			defFuncs: []elasticorm.IndexDefinitionFunc{
+				elasticorm.AddMappingFromStruct(
+					`customer`,
+					(func() interface{} {
+						type User struct {
+							Name string `json:"name" elasticorm:"autocomplete"`
+						}
+						return &User{}
+					})(),
+				),
+			},
+			expectedJSON: `{"settings":{"analysis":{"analyzer":{"autocomplete":{"type":"custom","tokenizer":"autocomplete","filter":["lowercase"]},"autocomplete_search":{"type":"custom","tokenizer":"standard","filter":["lowercase"]}},"tokenizer":{"autocomplete":{"type":"edge_ngram","token_chars":["letter","digit"],"min_gram":1,"max_gram":20}}}},"mappings":{"customer":{"properties":{"name":{"type":"text","fields":{"autocomplete":{"type":"text","analyzer":"autocomplete","search_analyzer":"autocomplete_search"}}}}}}}`,
+		},
+		{
+			title: `Index definition with a customer mapping with a type with an own analyzer`,
+			defFuncs: []elasticorm.IndexDefinitionFunc{
+				elasticorm.AddMappingFromStruct(
+					`customer`,
+					(func() interface{} {
+						type User struct {
+							Bio   LocalizedString  `json:"bio"`
+							Motto *LocalizedString `json:"motto"`
+						}
+						return &User{}
+					})(),
+				),
+			},
+			expectedJSON: `{"settings":{"analysis":{"analyzer":{"localized":{"type":"custom","tokenizer":"standard","filter":["lowercase","asciifolding"]}}}},"mappings":{"customer":{"properties":{"bio":{"type":"text","analyzer":"localized"},"motto":{"type":"text","analyzer":"localized"}}}}}`,
+		},
 	}
 
 	for _, tt := range tests {
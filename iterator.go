@@ -0,0 +1,126 @@
+package elasticorm
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	"github.com/olivere/elastic"
+)
+
+// iteratorBatchSize is the number of documents fetched from elasticsearch per scroll request
+const iteratorBatchSize = 100
+
+// Iterator streams all documents matching a query via the scroll API, without loading them into memory at once.
+//
+//	it := ds.NewIterator(ctx, query)
+//	defer it.Close()
+//	for it.Next() {
+//		u := &User{}
+//		if err := it.Decode(u); err != nil {
+//			return err
+//		}
+//	}
+//	return it.Err()
+type Iterator struct {
+	ds     *Datastore
+	ctx    context.Context
+	scroll *elastic.ScrollService
+	hits   []*elastic.SearchHit
+	pos    int
+	err    error
+	done   bool
+}
+
+// NewIterator returns an Iterator over all documents matching the query
+func (ds *Datastore) NewIterator(ctx context.Context, query Query) *Iterator {
+	it := &Iterator{ds: ds, ctx: ctx, pos: -1}
+	q, err := query.elasticQuery(ds.resolveFieldName)
+	if err != nil {
+		it.err = err
+		return it
+	}
+	it.scroll = ds.elasticClient.Scroll(ds.indexName).
+		Type(ds.typeName).
+		Query(q).
+		Size(iteratorBatchSize)
+	return it
+}
+
+// Next advances the iterator to the next document. It returns false, when there are no more documents or an error occurred
+func (it *Iterator) Next() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+	it.pos++
+	if it.pos < len(it.hits) {
+		return true
+	}
+	res, err := it.scroll.Do(it.ctx)
+	if err == io.EOF {
+		it.done = true
+		return false
+	}
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if res.Hits == nil || len(res.Hits.Hits) == 0 {
+		it.done = true
+		return false
+	}
+	it.hits = res.Hits.Hits
+	it.pos = 0
+	return true
+}
+
+// Decode decodes the current document into the passed in struct (pointer). It fails with ErrDecodeWithoutNext, when Next hasn't returned true before
+func (it *Iterator) Decode(o interface{}) error {
+	if it.pos < 0 || it.pos >= len(it.hits) {
+		return ErrDecodeWithoutNext
+	}
+	err := it.ds.isSaveableType(o)
+	if err != nil {
+		return err
+	}
+	return it.ds.decodeQueryResult(hitToResult(it.hits[it.pos]), o)
+}
+
+// Err returns the error, which stopped the iteration
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close releases the scroll context in elasticsearch
+func (it *Iterator) Close() error {
+	it.done = true
+	if it.scroll == nil {
+		return nil
+	}
+	return it.scroll.Clear(it.ctx)
+}
+
+// Iterate calls fn with each document matching the query, decoded into a new instance of the datastores struct (see ForStruct).
+// An error returned by fn stops the iteration and is returned
+func (ds *Datastore) Iterate(ctx context.Context, query Query, fn func(item interface{}) error) error {
+	if ds.goType == nil {
+		return ErrNoStruct
+	}
+	it := ds.NewIterator(ctx, query)
+	defer it.Close()
+
+	elemt := ds.goType
+	if elemt.Kind() == reflect.Ptr {
+		elemt = elemt.Elem()
+	}
+	for it.Next() {
+		item := reflect.New(elemt).Interface()
+		if err := it.Decode(item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
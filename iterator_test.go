@@ -0,0 +1,103 @@
+package elasticorm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+)
+
+func TestIterate(t *testing.T) {
+	type User struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Name   string `json:"name"`
+		Gender string `json:"gender" elasticorm:"type=keyword"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	users := []*User{}
+	for i := 0; i < 250; i++ {
+		gender := `female`
+		if i%5 == 0 {
+			gender = `male`
+		}
+		users = append(users, &User{Name: fmt.Sprintf("Unknown No. %d", i), Gender: gender})
+	}
+	res, err := ds.BulkCreate(users)
+	ok(t, err)
+	assert(t, !res.HasErrors(), `BulkCreate should not fail: %#v`, res.Failed)
+	ds.Refresh()
+
+	seen := map[string]bool{}
+	err = ds.Iterate(context.Background(), elasticorm.Term(`Gender`, `female`), func(item interface{}) error {
+		u := item.(*User)
+		assert(t, u.ID != ``, `The ID of the user should be set`)
+		equals(t, `female`, u.Gender)
+		seen[u.ID] = true
+		return nil
+	})
+	ok(t, err)
+	equals(t, 200, len(seen))
+
+	stop := errors.New(`stop`)
+	count := 0
+	err = ds.Iterate(context.Background(), elasticorm.Query{}, func(item interface{}) error {
+		count++
+		if count == 3 {
+			return stop
+		}
+		return nil
+	})
+	equals(t, stop, err)
+	equals(t, 3, count)
+}
+
+func TestIterator(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name"`
+	}
+	_, ds := initDatastore(t, &User{})
+	for i := 0; i < 3; i++ {
+		err := ds.Create(&User{Name: fmt.Sprintf("Unknown No. %d", i)})
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	it := ds.NewIterator(context.Background(), elasticorm.Query{})
+	defer it.Close()
+	names := map[string]bool{}
+	for it.Next() {
+		u := &User{}
+		err := it.Decode(u)
+		ok(t, err)
+		names[u.Name] = true
+	}
+	ok(t, it.Err())
+	equals(t, map[string]bool{`Unknown No. 0`: true, `Unknown No. 1`: true, `Unknown No. 2`: true}, names)
+	equals(t, elasticorm.ErrDecodeWithoutNext, it.Decode(&User{}))
+}
+
+func TestIteratorDecodeWithoutNext(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	it := ds.NewIterator(context.Background(), elasticorm.Query{})
+	defer it.Close()
+	equals(t, elasticorm.ErrDecodeWithoutNext, it.Decode(&User{}))
+}
+
+func TestIterateWithoutStruct(t *testing.T) {
+	ds, err := elasticorm.NewDatastore(nil)
+	ok(t, err)
+
+	err = ds.Iterate(context.Background(), elasticorm.Query{}, func(item interface{}) error {
+		return nil
+	})
+	equals(t, elasticorm.ErrNoStruct, err)
+}
@@ -0,0 +1,123 @@
+package elasticorm
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ElasticMapper is implemented by value types with an own mapping - e.g. Money or LocalizedString. MappingFromStruct uses
+// the mapping for all fields of the type instead of deriving it from the Go type. Tags on the fields are applied on top of it,
+// except for a type set by the tag, which replaces the mapping of the ElasticMapper completely.
+// The properties of the mapping are matched to the struct fields of the type by their JSON names
+type ElasticMapper interface {
+	ElasticMapping() MappingFieldConfig
+}
+
+// ElasticAnalysisMapper is implemented by ElasticMappers, which need own analyzers or tokenizers. AddMappingFromStruct
+// adds them to the index definition
+type ElasticAnalysisMapper interface {
+	ElasticAnalysis() IndexAnalysis
+}
+
+var (
+	elasticMapperType         = reflect.TypeOf((*ElasticMapper)(nil)).Elem()
+	elasticAnalysisMapperType = reflect.TypeOf((*ElasticAnalysisMapper)(nil)).Elem()
+)
+
+// elasticMapperForType returns the ElasticMapper of the values of a field, if their type implements it - with a value or a pointer receiver
+func elasticMapperForType(t reflect.Type) (ElasticMapper, bool) {
+	t = elemType(t)
+	if !reflect.PtrTo(t).Implements(elasticMapperType) {
+		return nil, false
+	}
+	return reflect.New(t).Interface().(ElasticMapper), true
+}
+
+// withStructFieldNames returns a copy of the properties with the struct fields of the type assigned by their JSON names,
+// so that the properties of an ElasticMapper can be referred to by the struct field names like all other fields
+func withStructFieldNames(properties map[string]MappingFieldConfig, t reflect.Type) map[string]MappingFieldConfig {
+	if len(properties) == 0 || t.Kind() != reflect.Struct {
+		return properties
+	}
+	named := make(map[string]MappingFieldConfig, len(properties))
+	for name, cfg := range properties {
+		named[name] = cfg
+	}
+	for _, f := range structFields(t) {
+		name := nameForField(f)
+		cfg, ok := named[name]
+		if !ok {
+			continue
+		}
+		cfg.structFieldName = f.Name
+		cfg.Properties = withStructFieldNames(cfg.Properties, elemType(f.Type))
+		named[name] = cfg
+	}
+	return named
+}
+
+// analysisForType collects the analyzers and tokenizers of all ElasticAnalysisMappers in the mapped fields of the struct (pointer) type
+func analysisForType(t reflect.Type) IndexAnalysis {
+	analysis := IndexAnalysis{}
+	collectAnalysis(&analysis, elemType(t), map[reflect.Type]bool{})
+	return analysis
+}
+
+func collectAnalysis(analysis *IndexAnalysis, t reflect.Type, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	if reflect.PtrTo(t).Implements(elasticAnalysisMapperType) {
+		a := reflect.New(t).Interface().(ElasticAnalysisMapper).ElasticAnalysis()
+		for name, analyzer := range a.Analyzer {
+			if analysis.Analyzer == nil {
+				analysis.Analyzer = map[string]Analyzer{}
+			}
+			analysis.Analyzer[name] = analyzer
+		}
+		for name, tokenizer := range a.Tokenizer {
+			if analysis.Tokenizer == nil {
+				analysis.Tokenizer = map[string]Tokenizer{}
+			}
+			analysis.Tokenizer[name] = tokenizer
+		}
+	}
+	if t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(elasticMapperType) {
+		return
+	}
+	for _, f := range structFields(t) {
+		if shouldMapField(f) {
+			collectAnalysis(analysis, elemType(f.Type), visited)
+		}
+	}
+}
+
+// addAnalysis adds the analyzers and tokenizers to the index definition. A name can only be used for one definition
+func (d *IndexDefinition) addAnalysis(analysis IndexAnalysis) error {
+	if len(analysis.Analyzer) == 0 && len(analysis.Tokenizer) == 0 {
+		return nil
+	}
+	if d.Settings.Analysis == nil {
+		d.Settings.Analysis = &IndexAnalysis{}
+	}
+	for name, analyzer := range analysis.Analyzer {
+		if existing, ok := d.Settings.Analysis.Analyzer[name]; ok && !reflect.DeepEqual(existing, analyzer) {
+			return fmt.Errorf("analyzer \"%s\" already set", name)
+		}
+		if d.Settings.Analysis.Analyzer == nil {
+			d.Settings.Analysis.Analyzer = map[string]Analyzer{}
+		}
+		d.Settings.Analysis.Analyzer[name] = analyzer
+	}
+	for name, tokenizer := range analysis.Tokenizer {
+		if existing, ok := d.Settings.Analysis.Tokenizer[name]; ok && !reflect.DeepEqual(existing, tokenizer) {
+			return fmt.Errorf("tokenizer \"%s\" already set", name)
+		}
+		if d.Settings.Analysis.Tokenizer == nil {
+			d.Settings.Analysis.Tokenizer = map[string]Tokenizer{}
+		}
+		d.Settings.Analysis.Tokenizer[name] = tokenizer
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package elasticorm_test
+
+import (
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+)
+
+func TestElasticMapperSubFields(t *testing.T) {
+	type Product struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Name  string `json:"name"`
+		Price Money  `json:"price"`
+	}
+
+	_, ds := initDatastore(t, &Product{})
+	err := ds.Create(&Product{Name: `Anvil`, Price: Money{Amount: 9950, Currency: `EUR`}})
+	ok(t, err)
+	err = ds.Create(&Product{Name: `Canoe`, Price: Money{Amount: 120000, Currency: `EUR`}})
+	ok(t, err)
+	ds.Refresh()
+
+	found := []Product{}
+	err = ds.FindAll(&found, ds.FilterRange(`Price.Amount`, int64(10000), nil), ds.FilterByField(`Price.Currency`, `EUR`))
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Canoe`, found[0].Name)
+
+	found = []Product{}
+	err = ds.FindAll(&found, ds.SetSorting(`Price.Amount`, `desc`))
+	ok(t, err)
+	equals(t, 2, len(found))
+	equals(t, `Canoe`, found[0].Name)
+}
+
+func TestElasticMapperSubFieldNames(t *testing.T) {
+	type Product struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Price Money  `json:"price"`
+	}
+	ds, err := elasticorm.NewDatastore(nil, elasticorm.ForStruct(&Product{}))
+	ok(t, err)
+
+	_, err = ds.ParseQuery(`Price.Amount>100 price.currency:EUR`)
+	ok(t, err)
+}
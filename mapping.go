@@ -1,9 +1,14 @@
 package elasticorm
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -29,9 +34,15 @@ func addAnalyzers(res map[string]bool, mapping MappingFieldConfig) {
 	if mapping.Analyzer != "" {
 		res[mapping.Analyzer] = true
 	}
+	if mapping.SearchAnalyzer != "" {
+		res[mapping.SearchAnalyzer] = true
+	}
 	for _, m := range mapping.Properties {
 		addAnalyzers(res, m)
 	}
+	for _, m := range mapping.Fields {
+		addAnalyzers(res, m)
+	}
 }
 
 // AddField adds a new field to the mapping
@@ -47,41 +58,88 @@ func (m MappingConfig) elasticFieldName(structFieldName string) (string, error)
 }
 
 func elasticFieldNameFromMappingFieldConfigs(fieldConfigs map[string]MappingFieldConfig, structFieldName string) (string, error) {
+	name, _, err := elasticFieldFromMappingFieldConfigs(fieldConfigs, structFieldName)
+	return name, err
+}
+
+func (m MappingConfig) elasticField(structFieldName string) (string, MappingFieldConfig, error) {
+	return elasticFieldFromMappingFieldConfigs(m.Properties, structFieldName)
+}
+
+// elasticFieldFromMappingFieldConfigs returns the elasticsearch field name and the mapping of the (dot separated) struct field path
+func elasticFieldFromMappingFieldConfigs(fieldConfigs map[string]MappingFieldConfig, structFieldName string) (string, MappingFieldConfig, error) {
 	structFieldNameParts := strings.Split(structFieldName, `.`)
 	structFieldName = structFieldNameParts[0]
 	for propertyName, propertyMapping := range fieldConfigs {
 		if propertyMapping.structFieldName == structFieldName {
 			if len(structFieldNameParts) > 1 {
 				subStructFieldPath := strings.Join(structFieldNameParts[1:], `.`)
-				subElasticFieldPath, err := elasticFieldNameFromMappingFieldConfigs(propertyMapping.Properties, subStructFieldPath)
+				subElasticFieldPath, subMapping, err := elasticFieldFromMappingFieldConfigs(propertyMapping.Properties, subStructFieldPath)
 				if err != nil {
-					return ``, err
+					return ``, MappingFieldConfig{}, err
 				}
-				propertyName = fmt.Sprintf("%s.%s", propertyName, subElasticFieldPath)
+				return fmt.Sprintf("%s.%s", propertyName, subElasticFieldPath), subMapping, nil
 			}
-			return propertyName, nil
+			return propertyName, propertyMapping, nil
+		}
+	}
+	return ``, MappingFieldConfig{}, errors.New(`Mapping configuration has no mapping for struct field`)
+}
+
+func (m MappingConfig) structFieldName(elasticFieldName string) (string, error) {
+	return structFieldNameFromMappingFieldConfigs(m.Properties, elasticFieldName)
+}
+
+// structFieldNameFromMappingFieldConfigs returns the (dot separated) struct field path of the elasticsearch field name
+func structFieldNameFromMappingFieldConfigs(fieldConfigs map[string]MappingFieldConfig, elasticFieldName string) (string, error) {
+	parts := strings.SplitN(elasticFieldName, `.`, 2)
+	propertyMapping, ok := fieldConfigs[parts[0]]
+	if !ok {
+		return ``, errors.New(`Mapping configuration has no struct field for mapped field`)
+	}
+	if len(parts) > 1 {
+		subStructFieldPath, err := structFieldNameFromMappingFieldConfigs(propertyMapping.Properties, parts[1])
+		if err != nil {
+			return ``, err
 		}
+		return propertyMapping.structFieldName + `.` + subStructFieldPath, nil
 	}
-	return ``, errors.New(`Mapping configuration has no mapping for struct field`)
+	return propertyMapping.structFieldName, nil
 }
 
 // MappingFieldConfig is a struct which represents the elasticsearch mapping configuration of one field. It is used in the MappingConfig.
 type MappingFieldConfig struct {
 	Type            string                        `json:"type"`
 	Analyzer        string                        `json:"analyzer,omitempty"`
+	SearchAnalyzer  string                        `json:"search_analyzer,omitempty"`
+	Normalizer      string                        `json:"normalizer,omitempty"`
+	Format          string                        `json:"format,omitempty"`
+	Index           *bool                         `json:"index,omitempty"`
+	Enabled         *bool                         `json:"enabled,omitempty"`
+	Dynamic         string                        `json:"dynamic,omitempty"`
+	DocValues       *bool                         `json:"doc_values,omitempty"`
+	IgnoreAbove     int                           `json:"ignore_above,omitempty"`
+	NullValue       interface{}                   `json:"null_value,omitempty"`
+	CopyTo          []string                      `json:"copy_to,omitempty"`
 	structFieldName string                        `json:"-"`
 	Properties      map[string]MappingFieldConfig `json:"properties,omitempty"`
 	Fields          map[string]MappingFieldConfig `json:"fields,omitempty"`
 	Similarity      string                        `json:"similarity,omitempty"`
+	Contexts        []MappingContextConfig        `json:"contexts,omitempty"`
+}
+
+// MappingContextConfig is a context of a completion field
+type MappingContextConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"`
 }
 
 // MappingFromStruct returns the MappingConfig for a passed in struct (pointer). The mapping is configurable via json tags, which can change the name of the field, and elasticorm tags. The elasticorm tags can include
 func MappingFromStruct(i interface{}) (MappingConfig, error) {
 	mapping := MappingConfig{}
 	var err error
-	v := reflect.ValueOf(i).Elem()
-	for n := 0; n < v.NumField(); n++ {
-		field := v.Type().Field(n)
+	for _, field := range structFields(reflect.TypeOf(i).Elem()) {
 		if !shouldMapField(field) {
 			continue
 		}
@@ -101,21 +159,89 @@ func mappingForField(field reflect.StructField) (MappingFieldConfig, error) {
 		Type:            typeForField(field),
 		structFieldName: field.Name,
 	}
-	propMapping.Properties, err = propertiesForField(field)
-	if err != nil {
-		return propMapping, err
+	_, hasTypeOption := typeOptionForField(field)
+	if mapper, ok := elasticMapperForType(field.Type); ok && !hasTypeOption {
+		propMapping = mapper.ElasticMapping()
+		propMapping.structFieldName = field.Name
+		propMapping.Properties = withStructFieldNames(propMapping.Properties, elemType(field.Type))
+	} else {
+		propMapping.Properties, err = propertiesForField(field)
+		if err != nil {
+			return propMapping, err
+		}
 	}
 	if tag := field.Tag.Get(`elasticorm`); tag != `` {
 		options := optionsFromTag(tag)
-		for name, value := range options {
+		if typ, ok := options[`type`]; ok && typ != `keyword` {
+			if _, ok := options[`keyword`]; ok {
+				return propMapping, errors.Wrap(ErrInvalidOption, fmt.Sprintf("keyword conflicts with type=%s on %s", typ, field.Name))
+			}
+		}
+		if analyzer, ok := options[`analyzer`]; ok && options[`case_sensitive`] == "false" {
+			return propMapping, errors.Wrap(ErrInvalidOption, fmt.Sprintf(
+				`trying to set case_sensitivity to false on \"%s\" while the analyzer is already set to \"%s\"`,
+				field.Name,
+				analyzer,
+			))
+		}
+		for _, option := range orderedOptionsFromTag(tag) {
+			name, value := option.name, option.value
 			switch name {
-			case `type`:
-				propMapping.Type = value
+			case `type`, `keyword`:
+				// resolved by typeForField
 			case `analyzer`:
 				propMapping.Analyzer = value
+			case `search_analyzer`:
+				propMapping.SearchAnalyzer = value
+			case `normalizer`:
+				propMapping.Normalizer = value
+			case `format`:
+				propMapping.Format = value
+			case `index`, `doc_values`:
+				if value != "true" && value != "false" {
+					return propMapping, errors.Wrap(ErrInvalidOption, fmt.Sprintf("flag %s must be true or false", name))
+				}
+				enabled := value == "true"
+				if name == `index` {
+					propMapping.Index = &enabled
+				} else {
+					propMapping.DocValues = &enabled
+				}
+			case `ignore_above`:
+				propMapping.IgnoreAbove, err = strconv.Atoi(value)
+				if err != nil || propMapping.IgnoreAbove < 1 {
+					return propMapping, errors.Wrap(ErrInvalidOption, fmt.Sprintf("ignore_above %s of %s must be a positive number", value, field.Name))
+				}
+			case `copy_to`:
+				propMapping.CopyTo = strings.Split(value, `;`)
+			case `null_value`:
+				// converted below, when the type is known
 			case `sortable`:
-				propMapping.Fields = rawFieldForField(field)
-			case `id`:
+				propMapping.Fields = addSubField(propMapping.Fields, `raw`, rawFieldForField(field)[`raw`])
+			case `geo_shape`:
+				propMapping.Type = `geo_shape`
+				propMapping.Properties = nil
+			case `suggest`:
+				propMapping.Type = `completion`
+				propMapping.Properties = nil
+				if value != `true` {
+					propMapping.Contexts = suggestContextsForOption(value)
+				}
+			case `fields`:
+				subFields, subErr := subFieldsForOption(value)
+				if subErr != nil {
+					return propMapping, errors.Wrap(ErrInvalidOption, fmt.Sprintf("fields of %s: %s", field.Name, subErr))
+				}
+				for subName, subCfg := range subFields {
+					propMapping.Fields = addSubField(propMapping.Fields, subName, subCfg)
+				}
+			case `autocomplete`:
+				propMapping.Fields = addSubField(propMapping.Fields, autocompleteSubField, autocompleteFieldConfig())
+			case `id`, `version`, `highlights`, `meta`, `facet`, `searchable`:
+			case `boost`:
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return propMapping, errors.Wrap(ErrInvalidOption, fmt.Sprintf("boost %s of %s must be a number", value, field.Name))
+				}
 			case "ref_id":
 				propMapping.Type = "keyword"
 				if propMapping.Analyzer == "case_insensitive_ref_id" {
@@ -143,17 +269,77 @@ func mappingForField(field reflect.StructField) (MappingFieldConfig, error) {
 				return propMapping, errors.Wrap(ErrInvalidOption, fmt.Sprintf("parsing option %s=%s failed", name, value))
 			}
 		}
+		if value, ok := options[`null_value`]; ok {
+			propMapping.NullValue, err = nullValueForType(propMapping.Type, value)
+			if err != nil {
+				return propMapping, errors.Wrap(ErrInvalidOption, fmt.Sprintf("null_value %s of %s: %s", value, field.Name, err))
+			}
+		}
+	}
+	if propMapping.Type == `object` || propMapping.Type == `nested` {
+		switch t := elemType(field.Type); {
+		case t == rawMessageType:
+			disabled := false
+			propMapping.Enabled = &disabled
+		case t.Kind() == reflect.Map:
+			propMapping.Dynamic = `true`
+		}
 	}
 	return propMapping, err
 }
 
+// nullValueForType converts the null_value option into a value of the elasticsearch type
+func nullValueForType(elasticType string, value string) (interface{}, error) {
+	switch elasticType {
+	case `boolean`:
+		return strconv.ParseBool(value)
+	case `long`, `integer`, `short`, `byte`:
+		return strconv.ParseInt(value, 10, 64)
+	case `double`, `float`, `half_float`, `scaled_float`:
+		return strconv.ParseFloat(value, 64)
+	}
+	return value, nil
+}
+
 func typeForField(f reflect.StructField) string {
-	if val, ok := optionValueForField(f, `type`); ok {
+	if val, ok := typeOptionForField(f); ok {
 		return val
 	}
 	return elasticTypeForGoType(f.Type)
 }
 
+// typeOptionForField returns the type set by the type option or the keyword flag. They are resolved before all other
+// options, so that the options, which depend on the type, see the same type regardless of their order in the tag
+func typeOptionForField(f reflect.StructField) (string, bool) {
+	if val, ok := optionValueForField(f, `type`); ok {
+		return val, true
+	}
+	if _, ok := optionValueForField(f, `keyword`); ok {
+		return `keyword`, true
+	}
+	return ``, false
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	ipType         = reflect.TypeOf(net.IP{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+)
+
+// elemType returns the type of the values of a field - the element type of pointers and slices, except for the slice types with an own mapping
+func elemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice && t != rawMessageType && t != ipType && t.Elem().Kind() != reflect.Uint8 {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
 func elasticTypeForGoType(t reflect.Type) string {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -161,6 +347,21 @@ func elasticTypeForGoType(t reflect.Type) string {
 	if t.PkgPath() == `time` && t.Name() == `Time` {
 		return `date`
 	}
+	switch t {
+	case reflect.TypeOf(GeoPoint{}):
+		return `geo_point`
+	case reflect.TypeOf(GeoShape{}):
+		return `geo_shape`
+	case rawMessageType:
+		return `object`
+	case ipType:
+		return `ip`
+	case durationType:
+		return `long`
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return `binary`
+	}
 	switch t.Kind() {
 	case reflect.Slice:
 		subtype := elasticTypeForGoType(t.Elem())
@@ -186,6 +387,15 @@ func elasticTypeForGoType(t reflect.Type) string {
 		return `integer`
 	case reflect.Int64:
 		return `long`
+	case reflect.Uint8:
+		return `short`
+	case reflect.Uint16:
+		return `integer`
+	// elasticsearch 5 has no unsigned_long, so values above math.MaxInt64 can't be stored
+	case reflect.Uint32, reflect.Uint, reflect.Uint64:
+		return `long`
+	case reflect.Map:
+		return `object`
 	default:
 		return `text`
 	}
@@ -207,17 +417,16 @@ func optionsForField(f reflect.StructField) map[string]string {
 }
 
 func propertiesForField(f reflect.StructField) (map[string]MappingFieldConfig, error) {
-	t := f.Type
-	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
-		t = t.Elem()
-	}
+	t := elemType(f.Type)
 	if typeForField(f) != `nested` && typeForField(f) != `object` {
 		return nil, nil
 	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
 	properties := make(map[string]MappingFieldConfig, t.NumField())
 	var err error
-	for n := 0; n < t.NumField(); n++ {
-		field := t.Field(n)
+	for _, field := range structFields(t) {
 		if shouldMapField(field) {
 			properties[nameForField(field)], err = mappingForField(field)
 		}
@@ -233,20 +442,135 @@ func rawFieldForField(f reflect.StructField) map[string]MappingFieldConfig {
 	return cfg
 }
 
+// subFieldsForOption parses the fields option - a semicolon separated list of sub fields with a name, a type and an
+// optional analyzer, e.g. elasticorm:"fields=raw:keyword;en:text/english"
+func subFieldsForOption(value string) (map[string]MappingFieldConfig, error) {
+	fields := make(map[string]MappingFieldConfig)
+	for _, definition := range strings.Split(value, `;`) {
+		nameAndType := strings.SplitN(definition, `:`, 2)
+		if len(nameAndType) != 2 || nameAndType[0] == `` || nameAndType[1] == `` {
+			return nil, errors.Errorf("sub field %s must be name:type or name:type/analyzer", definition)
+		}
+		typeAndAnalyzer := strings.SplitN(nameAndType[1], `/`, 2)
+		cfg := MappingFieldConfig{Type: typeAndAnalyzer[0]}
+		if len(typeAndAnalyzer) > 1 {
+			cfg.Analyzer = typeAndAnalyzer[1]
+		}
+		fields[nameAndType[0]] = cfg
+	}
+	return fields, nil
+}
+
+// sortFieldName returns the field to sort on for the field - the field itself or, for text fields, a keyword sub field
+func sortFieldName(elasticFieldName string, cfg MappingFieldConfig) (string, error) {
+	if cfg.Type != `text` {
+		return elasticFieldName, nil
+	}
+	if raw, ok := cfg.Fields[`raw`]; ok && raw.Type == `keyword` {
+		return elasticFieldName + `.raw`, nil
+	}
+	names := make([]string, 0, len(cfg.Fields))
+	for name, sub := range cfg.Fields {
+		if sub.Type == `keyword` {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ``, errors.Wrapf(ErrNotSortable, `%s is a text field without a keyword sub field`, elasticFieldName)
+	}
+	sort.Strings(names)
+	return elasticFieldName + `.` + names[0], nil
+}
+
+// addSubField adds the sub field to fields, which may be nil
+func addSubField(fields map[string]MappingFieldConfig, name string, cfg MappingFieldConfig) map[string]MappingFieldConfig {
+	if fields == nil {
+		fields = make(map[string]MappingFieldConfig, 1)
+	}
+	fields[name] = cfg
+	return fields
+}
+
 func shouldMapField(f reflect.StructField) bool {
 	_, isId := optionValueForField(f, `id`)
-	return !(isId || f.Tag.Get(`json`) == `-`)
+	_, isVersion := optionValueForField(f, `version`)
+	_, isHighlights := optionValueForField(f, `highlights`)
+	_, isMeta := optionValueForField(f, `meta`)
+	return !(isId || isVersion || isHighlights || isMeta || f.Tag.Get(`json`) == `-`)
+}
+
+// fieldNameWithOption returns the name of the first field of the struct (pointer) type, which has the elasticorm option set
+func fieldNameWithOption(t reflect.Type, option string) string {
+	names := fieldNamesWithOption(t, option)
+	if len(names) == 0 {
+		return ``
+	}
+	return names[0]
+}
+
+// fieldNamesWithOption returns the names of all fields of the struct (pointer) type, which have the elasticorm option set
+func fieldNamesWithOption(t reflect.Type, option string) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	names := []string{}
+	for _, f := range structFields(t) {
+		if _, ok := optionValueForField(f, option); ok {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// fieldNamesByOptionValue returns the names of all fields of the struct (pointer) type, which have the elasticorm option set, by its value.
+// Each value can only be used by one field
+func fieldNamesByOptionValue(t reflect.Type, option string) (map[string]string, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	names := make(map[string]string)
+	for _, f := range structFields(t) {
+		if value, ok := optionValueForField(f, option); ok {
+			if other, ok := names[value]; ok {
+				return nil, errors.Wrap(ErrInvalidOption, fmt.Sprintf("%s=%s is used by %s and %s", option, value, other, f.Name))
+			}
+			names[value] = f.Name
+		}
+	}
+	return names, nil
 }
 
 func optionsFromTag(tag string) map[string]string {
 	options := make(map[string]string, 2)
+	for _, option := range orderedOptionsFromTag(tag) {
+		options[option.name] = option.value
+	}
+	return options
+}
+
+// tagOption is a name=value option of an elasticorm tag. Flags without a value are true
+type tagOption struct {
+	name  string
+	value string
+}
+
+// orderedOptionsFromTag returns the options in the order of the tag, so that they are applied deterministically.
+// Only the first = separates the name from the value, which can contain = itself
+func orderedOptionsFromTag(tag string) []tagOption {
 	definitions := strings.Split(tag, `,`)
+	options := make([]tagOption, 0, len(definitions))
 	for _, definition := range definitions {
-		kv := strings.Split(definition, `=`)
+		kv := strings.SplitN(definition, `=`, 2)
 		if len(kv) > 1 {
-			options[kv[0]] = kv[1]
+			options = append(options, tagOption{name: kv[0], value: kv[1]})
 		} else {
-			options[kv[0]] = "true"
+			options = append(options, tagOption{name: kv[0], value: "true"})
 		}
 	}
 	return options
@@ -1,6 +1,7 @@
 package elasticorm_test
 
 import (
+	"net"
 	"testing"
 	"time"
 
@@ -10,6 +11,37 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Money has an own mapping
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) ElasticMapping() elasticorm.MappingFieldConfig {
+	return elasticorm.MappingFieldConfig{
+		Type: `object`,
+		Properties: map[string]elasticorm.MappingFieldConfig{
+			`amount`:   {Type: `long`},
+			`currency`: {Type: `keyword`},
+		},
+	}
+}
+
+// LocalizedString is marshalled as a JSON string and has an own mapping with an own analyzer
+type LocalizedString string
+
+func (s *LocalizedString) ElasticMapping() elasticorm.MappingFieldConfig {
+	return elasticorm.MappingFieldConfig{Type: `text`, Analyzer: `localized`}
+}
+
+func (s *LocalizedString) ElasticAnalysis() elasticorm.IndexAnalysis {
+	return elasticorm.IndexAnalysis{
+		Analyzer: map[string]elasticorm.Analyzer{
+			`localized`: {Type: `custom`, Tokenizer: `standard`, Filter: []string{`lowercase`, `asciifolding`}},
+		},
+	}
+}
+
 type mappingTestCase struct {
 	Title         string
 	Input         interface{}
@@ -116,6 +148,346 @@ var mappingTestCases []mappingTestCase = []mappingTestCase{
 		ExpectedJSON:  `{"properties":{"first_name":{"type":"text"}}}`,
 		ExpectedError: nil,
 	},
+	mappingTestCase{
+		Title: `For a struct with a version field - which should not be mapped`,
+		Input: func() interface{} {
+			type User struct {
+				ID        string `elasticorm:"id"`
+				Version   int64  `json:"-" elasticorm:"version"`
+				FirstName string `json:"first_name"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"first_name":{"type":"text"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with a highlights field - which should not be mapped`,
+		Input: func() interface{} {
+			type User struct {
+				ID         string              `elasticorm:"id"`
+				FirstName  string              `json:"first_name"`
+				Highlights map[string][]string `json:"highlights" elasticorm:"highlights"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"first_name":{"type":"text"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with meta fields - which should not be mapped`,
+		Input: func() interface{} {
+			type User struct {
+				ID        string        `elasticorm:"id"`
+				FirstName string        `json:"first_name"`
+				Score     float64       `json:"score" elasticorm:"meta=score"`
+				Index     string        `json:"index" elasticorm:"meta=index"`
+				Sort      []interface{} `json:"sort" elasticorm:"meta=sort"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"first_name":{"type":"text"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with a sortable autocomplete field`,
+		Input: func() interface{} {
+			type User struct {
+				Name string `json:"name" elasticorm:"sortable,autocomplete"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"name":{"type":"text","fields":{"autocomplete":{"type":"text","analyzer":"autocomplete","search_analyzer":"autocomplete_search"},"raw":{"type":"keyword"}}}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with suggest fields`,
+		Input: func() interface{} {
+			type Movie struct {
+				Title    string   `json:"title" elasticorm:"suggest"`
+				Tags     []string `json:"tags" elasticorm:"suggest=genre;language"`
+				Genre    string   `json:"genre" elasticorm:"type=keyword"`
+				Language string   `json:"language" elasticorm:"type=keyword"`
+			}
+			return &Movie{}
+		}(),
+		ExpectedJSON:  `{"properties":{"genre":{"type":"keyword"},"language":{"type":"keyword"},"tags":{"type":"completion","contexts":[{"name":"genre","type":"category","path":"genre"},{"name":"language","type":"category","path":"language"}]},"title":{"type":"completion"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with searchable fields`,
+		Input: func() interface{} {
+			type Article struct {
+				Title string `json:"title" elasticorm:"searchable,boost=2"`
+				Body  string `json:"body" elasticorm:"searchable"`
+			}
+			return &Article{}
+		}(),
+		ExpectedJSON:  `{"properties":{"body":{"type":"text"},"title":{"type":"text"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with an invalid boost`,
+		Input: func() interface{} {
+			type Article struct {
+				Title string `json:"title" elasticorm:"searchable,boost=high"`
+			}
+			return &Article{}
+		}(),
+		ExpectedJSON:  `{"properties":{"title":{"type":"text"}}}`,
+		ExpectedError: errors.Wrap(elasticorm.ErrInvalidOption, `boost high of Title must be a number`),
+	},
+	mappingTestCase{
+		Title: `For a struct with geo types`,
+		Input: func() interface{} {
+			type Isle struct {
+				Location  elasticorm.GeoPoint    `json:"loc"`
+				Harbours  []elasticorm.GeoPoint  `json:"harbours"`
+				Coastline *elasticorm.GeoShape   `json:"coastline"`
+				Area      map[string]interface{} `json:"area" elasticorm:"geo_shape"`
+			}
+			return &Isle{}
+		}(),
+		ExpectedJSON:  `{"properties":{"area":{"type":"geo_shape"},"coastline":{"type":"geo_shape"},"harbours":{"type":"geo_point"},"loc":{"type":"geo_point"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with field settings`,
+		Input: func() interface{} {
+			type User struct {
+				Email    string `json:"email" elasticorm:"keyword,normalizer=lowercase,ignore_above=256,null_value=none"`
+				Password string `json:"password" elasticorm:"keyword,index=false,doc_values=false"`
+				First    string `json:"first" elasticorm:"copy_to=full_name"`
+				Last     string `json:"last" elasticorm:"copy_to=full_name;names"`
+				FullName string `json:"full_name" elasticorm:"analyzer=standard,search_analyzer=simple"`
+				Born     string `json:"born" elasticorm:"type=date,format=yyyy-MM-dd"`
+				Age      int    `json:"age" elasticorm:"null_value=0"`
+				Active   bool   `json:"active" elasticorm:"null_value=false"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"active":{"type":"boolean","null_value":false},"age":{"type":"integer","null_value":0},"born":{"type":"date","format":"yyyy-MM-dd"},"email":{"type":"keyword","normalizer":"lowercase","ignore_above":256,"null_value":"none"},"first":{"type":"text","copy_to":["full_name"]},"full_name":{"type":"text","analyzer":"standard","search_analyzer":"simple"},"last":{"type":"text","copy_to":["full_name","names"]},"password":{"type":"keyword","index":false,"doc_values":false}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with an invalid ignore_above`,
+		Input: func() interface{} {
+			type User struct {
+				Email string `json:"email" elasticorm:"keyword,ignore_above=long"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"email":{"type":"keyword"}}}`,
+		ExpectedError: errors.Wrap(elasticorm.ErrInvalidOption, `ignore_above long of Email must be a positive number`),
+	},
+	mappingTestCase{
+		Title: `For a struct with the keyword flag combined with type dependent options`,
+		Input: func() interface{} {
+			type User struct {
+				Email string `json:"email" elasticorm:"keyword,case_sensitive=false"`
+				Title string `json:"title" elasticorm:"keyword,suggest"`
+				Area  string `json:"area" elasticorm:"keyword,geo_shape"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"area":{"type":"geo_shape"},"email":{"type":"text","analyzer":"case_insensitive_ref_id"},"title":{"type":"completion"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with the keyword flag conflicting with the type`,
+		Input: func() interface{} {
+			type User struct {
+				Email string `json:"email" elasticorm:"type=text,keyword"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"email":{"type":"text"}}}`,
+		ExpectedError: errors.Wrap(elasticorm.ErrInvalidOption, `keyword conflicts with type=text on Email`),
+	},
+	mappingTestCase{
+		Title: `For a struct with case_sensitive=false before an analyzer`,
+		Input: func() interface{} {
+			type User struct {
+				Email string `json:"email" elasticorm:"case_sensitive=false,analyzer=simple"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"email":{"type":"text"}}}`,
+		ExpectedError: errors.Wrap(elasticorm.ErrInvalidOption, `trying to set case_sensitivity to false on \"Email\" while the analyzer is already set to \"simple\"`),
+	},
+	mappingTestCase{
+		Title: `For a struct with a null_value containing =`,
+		Input: func() interface{} {
+			type User struct {
+				Email string `json:"email" elasticorm:"keyword,null_value=a=b"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"email":{"type":"keyword","null_value":"a=b"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with sub fields`,
+		Input: func() interface{} {
+			type Article struct {
+				Title string `json:"title" elasticorm:"fields=raw:keyword;en:text/english"`
+			}
+			return &Article{}
+		}(),
+		ExpectedJSON:  `{"properties":{"title":{"type":"text","fields":{"en":{"type":"text","analyzer":"english"},"raw":{"type":"keyword"}}}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with an invalid sub field`,
+		Input: func() interface{} {
+			type Article struct {
+				Title string `json:"title" elasticorm:"fields=raw"`
+			}
+			return &Article{}
+		}(),
+		ExpectedJSON:  `{"properties":{"title":{"type":"text"}}}`,
+		ExpectedError: errors.Wrap(elasticorm.ErrInvalidOption, `fields of Title: sub field raw must be name:type or name:type/analyzer`),
+	},
+	mappingTestCase{
+		Title: `For a struct with unsigned integers`,
+		Input: func() interface{} {
+			type Counter struct {
+				Tiny   uint8  `json:"tiny"`
+				Small  uint16 `json:"small"`
+				Medium uint32 `json:"medium"`
+				Large  uint64 `json:"large"`
+				Plain  uint   `json:"plain"`
+			}
+			return &Counter{}
+		}(),
+		ExpectedJSON:  `{"properties":{"large":{"type":"long"},"medium":{"type":"long"},"plain":{"type":"long"},"small":{"type":"integer"},"tiny":{"type":"short"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with IPs`,
+		Input: func() interface{} {
+			type Server struct {
+				IP        net.IP   `json:"ip"`
+				Fallbacks []net.IP `json:"fallbacks"`
+			}
+			return &Server{}
+		}(),
+		ExpectedJSON:  `{"properties":{"fallbacks":{"type":"ip"},"ip":{"type":"ip"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with binary data`,
+		Input: func() interface{} {
+			type File struct {
+				Content []byte `json:"content"`
+			}
+			return &File{}
+		}(),
+		ExpectedJSON:  `{"properties":{"content":{"type":"binary"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with a duration`,
+		Input: func() interface{} {
+			type Job struct {
+				Timeout  time.Duration  `json:"timeout"`
+				Duration *time.Duration `json:"duration"`
+			}
+			return &Job{}
+		}(),
+		ExpectedJSON:  `{"properties":{"duration":{"type":"long"},"timeout":{"type":"long"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with maps`,
+		Input: func() interface{} {
+			type Product struct {
+				Labels     map[string]string      `json:"labels"`
+				Attributes map[string]interface{} `json:"attributes"`
+				Variants   []map[string]int       `json:"variants"`
+			}
+			return &Product{}
+		}(),
+		ExpectedJSON:  `{"properties":{"attributes":{"type":"object","dynamic":"true"},"labels":{"type":"object","dynamic":"true"},"variants":{"type":"nested","dynamic":"true"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with raw JSON`,
+		Input: func() interface{} {
+			type Event struct {
+				Payload json.RawMessage  `json:"payload"`
+				Extra   *json.RawMessage `json:"extra"`
+			}
+			return &Event{}
+		}(),
+		ExpectedJSON:  `{"properties":{"extra":{"type":"object","enabled":false},"payload":{"type":"object","enabled":false}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with types with an own mapping`,
+		Input: func() interface{} {
+			type Product struct {
+				Price    Money            `json:"price"`
+				Discount *Money           `json:"discount"`
+				Prices   []Money          `json:"prices"`
+				Title    LocalizedString  `json:"title" elasticorm:"sortable"`
+				Subtitle *LocalizedString `json:"subtitle" elasticorm:"type=keyword"`
+			}
+			return &Product{}
+		}(),
+		ExpectedJSON:  `{"properties":{"discount":{"type":"object","properties":{"amount":{"type":"long"},"currency":{"type":"keyword"}}},"price":{"type":"object","properties":{"amount":{"type":"long"},"currency":{"type":"keyword"}}},"prices":{"type":"object","properties":{"amount":{"type":"long"},"currency":{"type":"keyword"}}},"subtitle":{"type":"keyword"},"title":{"type":"text","analyzer":"localized","fields":{"raw":{"type":"keyword"}}}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with embedded structs`,
+		Input: func() interface{} {
+			type Base struct {
+				ID      string    `elasticorm:"id"`
+				Created time.Time `json:"created"`
+			}
+			type address struct {
+				Street string `json:"street"`
+			}
+			type Contact struct {
+				Email string `json:"email" elasticorm:"type=keyword"`
+			}
+			type User struct {
+				Base
+				*address
+				Contact `json:"contact"`
+				Name    string `json:"name"`
+			}
+			return &User{}
+		}(),
+		ExpectedJSON:  `{"properties":{"contact":{"type":"object","properties":{"email":{"type":"keyword"}}},"created":{"type":"date"},"name":{"type":"text"},"street":{"type":"text"}}}`,
+		ExpectedError: nil,
+	},
+	mappingTestCase{
+		Title: `For a struct with conflicting embedded fields`,
+		Input: func() interface{} {
+			type Audit struct {
+				Note    string
+				Comment string
+				Kind    string `elasticorm:"type=keyword"`
+			}
+			type Review struct {
+				Note    string
+				Comment string
+			}
+			type Tagged struct {
+				Comment string `json:"Comment" elasticorm:"type=keyword"`
+			}
+			type Ticket struct {
+				Audit
+				Review
+				Tagged
+				Kind string
+			}
+			return &Ticket{}
+		}(),
+		ExpectedJSON:  `{"properties":{"Comment":{"type":"keyword"},"Kind":{"type":"text"}}}`,
+		ExpectedError: nil,
+	},
 	mappingTestCase{
 		Title: `For a nested struct with an anonymous`,
 		Input: func() interface{} {
@@ -0,0 +1,64 @@
+package elasticorm
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	metaScore   = `score`
+	metaIndex   = `index`
+	metaVersion = `version`
+	metaSort    = `sort`
+)
+
+// metaFieldNames returns the names of the fields tagged with elasticorm:"meta=..." by the kind of metadata
+func metaFieldNames(t reflect.Type) (map[string]string, error) {
+	names, err := fieldNamesByOptionValue(t, `meta`)
+	if err != nil {
+		return nil, err
+	}
+	for kind, fieldName := range names {
+		switch kind {
+		case metaScore, metaIndex, metaVersion, metaSort:
+		case `seq_no`:
+			return nil, errors.Wrapf(ErrInvalidOption, "meta seq_no of field %s is not supported, elasticsearch 5 doesn't return sequence numbers", fieldName)
+		default:
+			return nil, errors.Wrapf(ErrInvalidOption, "unknown meta %s of field %s", kind, fieldName)
+		}
+	}
+	return names, nil
+}
+
+// setMetadata writes the metadata into the fields tagged with elasticorm:"meta=...". Fields of an unsuitable type are skipped
+func (ds *Datastore) setMetadata(o interface{}, meta Metadata) {
+	if len(ds.metaFieldNames) == 0 {
+		return
+	}
+	eo := reflect.ValueOf(o).Elem()
+	for kind, fieldName := range ds.metaFieldNames {
+		field := fieldByName(eo, fieldName, true)
+		if !field.IsValid() || !field.CanSet() {
+			continue
+		}
+		switch kind {
+		case metaScore:
+			if meta.Score != nil && (field.Kind() == reflect.Float64 || field.Kind() == reflect.Float32) {
+				field.SetFloat(*meta.Score)
+			}
+		case metaIndex:
+			if field.Kind() == reflect.String {
+				field.SetString(meta.Index)
+			}
+		case metaVersion:
+			if meta.Version != nil && isIntKind(field.Kind()) {
+				field.SetInt(*meta.Version)
+			}
+		case metaSort:
+			if field.Type() == reflect.TypeOf([]interface{}{}) {
+				field.Set(reflect.ValueOf(meta.Sort))
+			}
+		}
+	}
+}
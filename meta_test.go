@@ -0,0 +1,73 @@
+package elasticorm_test
+
+import (
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestMetaFields(t *testing.T) {
+	type User struct {
+		ID      string        `json:"id" elasticorm:"id"`
+		Name    string        `json:"name" elasticorm:"sortable"`
+		Score   float64       `json:"-" elasticorm:"meta=score"`
+		Index   string        `json:"-" elasticorm:"meta=index"`
+		Version int64         `json:"-" elasticorm:"meta=version"`
+		Sort    []interface{} `json:"-" elasticorm:"meta=sort"`
+	}
+
+	_, ds := initDatastore(t, &User{})
+	err := ds.Create(&User{Name: `Frederic`})
+	ok(t, err)
+	ds.Refresh()
+
+	found := []User{}
+	err = ds.Search(elasticorm.Match(`Name`, `Frederic`).WithQueryContext(true), &found)
+	ok(t, err)
+	equals(t, 1, len(found))
+	assert(t, found[0].Score > 0, `expected a score, got %f`, found[0].Score)
+	equals(t, `users`, found[0].Index)
+	equals(t, int64(1), found[0].Version)
+
+	found = []User{}
+	err = ds.FindAll(&found, ds.SetSorting(`Name`, `asc`))
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, []interface{}{`Frederic`}, found[0].Sort)
+
+	user := User{}
+	err = ds.Find(found[0].ID, &user)
+	ok(t, err)
+	equals(t, `users`, user.Index)
+	equals(t, int64(1), user.Version)
+}
+
+func TestUnknownMetaField(t *testing.T) {
+	type User struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Shard string `json:"-" elasticorm:"meta=shard"`
+	}
+	_, err := elasticorm.NewDatastore(nil, elasticorm.ForStruct(&User{}))
+	equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+}
+
+func TestSeqNoMetaFieldIsRejected(t *testing.T) {
+	type User struct {
+		ID    string `json:"id" elasticorm:"id"`
+		SeqNo int64  `json:"-" elasticorm:"meta=seq_no"`
+	}
+	_, err := elasticorm.NewDatastore(nil, elasticorm.ForStruct(&User{}))
+	equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+}
+
+func TestDuplicateMetaField(t *testing.T) {
+	type User struct {
+		ID    string  `json:"id" elasticorm:"id"`
+		Score float64 `json:"-" elasticorm:"meta=score"`
+		Rank  float64 `json:"-" elasticorm:"meta=score"`
+	}
+	_, err := elasticorm.NewDatastore(nil, elasticorm.ForStruct(&User{}))
+	equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+	equals(t, `meta=score is used by Score and Rank: Invalid elasticorm option is used`, err.Error())
+}
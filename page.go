@@ -0,0 +1,70 @@
+package elasticorm
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/olivere/elastic"
+)
+
+// Page is returned by the paginated find methods and describes which part of all matching documents has been found
+type Page struct {
+	Items          interface{}   // the decoded slice of found documents
+	TotalHits      int64         // the number of all matching documents
+	TotalHitsExact bool          // false, when TotalHits is only a lower bound
+	Offset         int           // of the first found document
+	Limit          int           // maximum number of documents per page
+	HasNext        bool          // wether there are more matching documents after this page
+	NextCursor     string        // continues after the last document of this page, when passed to After. Empty without a next page
+	Took           time.Duration // the time elasticsearch took to execute the query
+}
+
+// FindPage finds all documents like FindAll, but returns the found documents along with the page information.
+// The page is selected with the Offset and Limit QueryOptFuncs
+func (ds *Datastore) FindPage(results interface{}, opts ...QueryOptFunc) (Page, error) {
+	return ds.findPage(elastic.NewMatchAllQuery(), results, opts)
+}
+
+// SearchPage executes the Query like Search, but returns the found documents along with the page information.
+// The page is selected with the Offset and Limit QueryOptFuncs
+func (ds *Datastore) SearchPage(query Query, results interface{}, opts ...QueryOptFunc) (Page, error) {
+	q, err := query.elasticQuery(ds.resolveFieldName)
+	if err != nil {
+		return Page{}, err
+	}
+	return ds.findPage(q, results, opts)
+}
+
+func (ds *Datastore) findPage(query elastic.Query, results interface{}, opts []QueryOptFunc) (Page, error) {
+	res, sq, err := ds.executeSearch(query, append([]QueryOptFunc{ds.withCursor()}, opts...))
+	if err != nil {
+		return Page{}, err
+	}
+	hits := res.Hits.Hits
+	// the additional hit fetched by withCursor only tells that there is a next page
+	hasNext := len(hits) > sq.limit
+	if hasNext {
+		hits = hits[:sq.limit]
+	}
+	err = ds.DecodeElasticResponses(hitsToResults(hits), results)
+	if err != nil {
+		return Page{}, err
+	}
+	page := Page{
+		Items:     reflect.ValueOf(results).Elem().Interface(),
+		TotalHits: res.TotalHits(),
+		// elasticsearch versions supported by this package always count all hits
+		TotalHitsExact: true,
+		Offset:         sq.offset,
+		Limit:          sq.limit,
+		HasNext:        hasNext,
+		Took:           time.Duration(res.TookInMillis) * time.Millisecond,
+	}
+	if hasNext && len(hits) > 0 {
+		page.NextCursor, err = encodeCursor(hits[len(hits)-1].Sort)
+		if err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,146 @@
+package elasticorm_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestFindPage(t *testing.T) {
+	type User struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Name   string `json:"name" elasticorm:"sortable"`
+		Gender string `json:"gender" elasticorm:"type=keyword"`
+	}
+
+	_, ds := initDatastore(t, &User{})
+	for i := 0; i < 7; i++ {
+		gender := `female`
+		if i%2 == 1 {
+			gender = `male`
+		}
+		err := ds.Create(&User{Name: fmt.Sprintf("Unknown No. %d", i), Gender: gender})
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	tests := []struct {
+		title         string
+		opts          []elasticorm.QueryOptFunc
+		expectedNames []string
+		expectedPage  elasticorm.Page
+	}{
+		{
+			title:         `Default page`,
+			expectedNames: []string{`Unknown No. 0`, `Unknown No. 1`, `Unknown No. 2`, `Unknown No. 3`, `Unknown No. 4`, `Unknown No. 5`, `Unknown No. 6`},
+			expectedPage:  elasticorm.Page{TotalHits: 7, TotalHitsExact: true, Offset: 0, Limit: 10, HasNext: false},
+		},
+		{
+			title:         `First page`,
+			opts:          []elasticorm.QueryOptFunc{ds.Offset(0), ds.Limit(3)},
+			expectedNames: []string{`Unknown No. 0`, `Unknown No. 1`, `Unknown No. 2`},
+			expectedPage:  elasticorm.Page{TotalHits: 7, TotalHitsExact: true, Offset: 0, Limit: 3, HasNext: true},
+		},
+		{
+			title:         `Last page`,
+			opts:          []elasticorm.QueryOptFunc{ds.Offset(6), ds.Limit(3)},
+			expectedNames: []string{`Unknown No. 6`},
+			expectedPage:  elasticorm.Page{TotalHits: 7, TotalHitsExact: true, Offset: 6, Limit: 3, HasNext: false},
+		},
+		{
+			title:         `Filtered page`,
+			opts:          []elasticorm.QueryOptFunc{ds.FilterByField(`Gender`, `male`), ds.Limit(2)},
+			expectedNames: []string{`Unknown No. 1`, `Unknown No. 3`},
+			expectedPage:  elasticorm.Page{TotalHits: 3, TotalHitsExact: true, Offset: 0, Limit: 2, HasNext: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			found := []User{}
+			page, err := ds.FindPage(&found, append(tt.opts, ds.SetSorting(`Name`, `asc`))...)
+			ok(t, err)
+
+			equals(t, len(tt.expectedNames), len(found))
+			for n, name := range tt.expectedNames {
+				equals(t, name, found[n].Name)
+			}
+			equals(t, found, page.Items)
+			// a cursor is only returned, when there is a next page to continue with
+			equals(t, page.HasNext, page.NextCursor != ``)
+			tt.expectedPage.Items = page.Items
+			tt.expectedPage.NextCursor = page.NextCursor
+			tt.expectedPage.Took = page.Took
+			equals(t, tt.expectedPage, page)
+		})
+	}
+}
+
+func TestFindPageWithCursor(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name" elasticorm:"sortable"`
+	}
+
+	_, ds := initDatastore(t, &User{})
+	for i := 0; i < 5; i++ {
+		err := ds.Create(&User{Name: fmt.Sprintf("Unknown No. %d", i)})
+		ok(t, err)
+	}
+	// a duplicate name is ordered by the tie breaker
+	err := ds.Create(&User{Name: `Unknown No. 2`})
+	ok(t, err)
+	ds.Refresh()
+
+	names := []string{}
+	pages := 0
+	opts := []elasticorm.QueryOptFunc{ds.Limit(2), ds.SetSorting(`Name`, `asc`)}
+	for ; pages < 10; pages++ {
+		found := []User{}
+		page, err := ds.FindPage(&found, opts...)
+		ok(t, err)
+		for _, u := range found {
+			names = append(names, u.Name)
+		}
+		if !page.HasNext {
+			break
+		}
+		opts = []elasticorm.QueryOptFunc{ds.Limit(2), ds.SetSorting(`Name`, `asc`), ds.After(page.NextCursor)}
+	}
+
+	equals(t, []string{`Unknown No. 0`, `Unknown No. 1`, `Unknown No. 2`, `Unknown No. 2`, `Unknown No. 3`, `Unknown No. 4`}, names)
+	// the last page is exactly full, but has no next page
+	equals(t, 2, pages)
+}
+
+func TestAfterWithOffset(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name" elasticorm:"sortable"`
+	}
+	_, ds := initDatastore(t, &User{})
+	err := ds.Create(&User{Name: `Unknown No. 0`})
+	ok(t, err)
+	ds.Refresh()
+
+	found := []User{}
+	page, err := ds.FindPage(&found, ds.Limit(1), ds.SetSorting(`Name`, `asc`))
+	ok(t, err)
+
+	_, err = ds.FindPage(&found, ds.Offset(1), ds.SetSorting(`Name`, `asc`), ds.After(page.NextCursor))
+	equals(t, elasticorm.ErrInvalidOption, errors.Cause(err))
+}
+
+func TestAfterWithInvalidCursor(t *testing.T) {
+	type User struct {
+		ID   string `json:"id" elasticorm:"id"`
+		Name string `json:"name" elasticorm:"sortable"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	found := []User{}
+	_, err := ds.FindPage(&found, ds.After(`not a cursor`))
+	equals(t, elasticorm.ErrInvalidCursor, errors.Cause(err))
+}
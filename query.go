@@ -1,16 +1,215 @@
 package elasticorm
 
-type Query struct{}
+import (
+	"encoding/json"
+
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// Query is a composable query, which refers to the names of the struct fields instead of the elasticsearch field names.
+// The zero value matches all documents. Queries are built with Term, Terms, Match, Range, Exists, Prefix, And, Or and Not
+// and executed with Datastore.Search
+type Query struct {
+	kind         string
+	field        string
+	value        interface{}
+	values       []interface{}
+	gte          interface{}
+	lte          interface{}
+	gt           interface{}
+	lt           interface{}
+	children     []Query
+	queryContext bool
+	contextSet   bool // wether the context has been set with WithQueryContext, instead of being inherited from And, Or or Not
+}
+
+const (
+	queryKindTerm   = `term`
+	queryKindTerms  = `terms`
+	queryKindMatch  = `match`
+	queryKindRange  = `range`
+	queryKindExists = `exists`
+	queryKindPrefix = `prefix`
+	queryKindAnd    = `and`
+	queryKindOr     = `or`
+	queryKindNot    = `not`
+)
+
+// Term returns a query for documents with exactly the value in the field
+func Term(fieldName string, value interface{}) Query {
+	return Query{kind: queryKindTerm, field: fieldName, value: value}
+}
+
+// Terms returns a query for documents with one of the values in the field
+func Terms(fieldName string, values ...interface{}) Query {
+	return Query{kind: queryKindTerms, field: fieldName, values: values}
+}
+
+// Match returns a full text query for the field
+func Match(fieldName string, text interface{}) Query {
+	return Query{kind: queryKindMatch, field: fieldName, value: text}
+}
+
+// Range returns a query for documents with a value between gte and lte (both inclusive). A nil bound is omitted
+func Range(fieldName string, gte, lte interface{}) Query {
+	return Query{kind: queryKindRange, field: fieldName, gte: gte, lte: lte}
+}
+
+// Exists returns a query for documents with any value in the field
+func Exists(fieldName string) Query {
+	return Query{kind: queryKindExists, field: fieldName}
+}
+
+// Prefix returns a query for documents with a value in the field starting with prefix
+func Prefix(fieldName string, prefix string) Query {
+	return Query{kind: queryKindPrefix, field: fieldName, value: prefix}
+}
+
+// And returns a query for documents matching all of the passed in queries. Empty queries are skipped
+func And(queries ...Query) Query {
+	return Query{kind: queryKindAnd, children: queries}
+}
+
+// Or returns a query for documents matching at least one of the passed in queries. An empty query matches all documents
+func Or(queries ...Query) Query {
+	return Query{kind: queryKindOr, children: queries}
+}
+
+// Not returns a query for documents not matching the passed in query
+func Not(query Query) Query {
+	return Query{kind: queryKindNot, children: []Query{query}}
+}
+
+// WithQueryContext returns a copy of the query, which is executed in query context (with a calculated score) - true -
+// or in filter context (hard matches) - false -. Queries passed to And, Or and Not inherit the context, unless it is set on them
+func (q Query) WithQueryContext(queryContext bool) Query {
+	q.queryContext = queryContext
+	q.contextSet = true
+	return q
+}
 
 func (q Query) String() string {
-	if q.QueryContext() {
-	} else {
+	eq, err := q.elasticQuery(func(fieldName string) (string, error) {
+		return fieldName, nil
+	})
+	if err != nil {
+		return err.Error()
+	}
+	src, err := eq.Source()
+	if err != nil {
+		return err.Error()
 	}
-	return ""
+	JSON, err := json.Marshal(src)
+	if err != nil {
+		return err.Error()
+	}
+	return string(JSON)
 }
 
 // QueryContext returns a boolean indicating wether the resulting query should be for query context (with a calculated score) - true -
 // or for filtering context (hard matches) - false -
 func (q Query) QueryContext() bool {
-	return false
+	return q.queryContext
+}
+
+// fieldNameResolver translates a struct field name into the elasticsearch field name
+type fieldNameResolver func(fieldName string) (string, error)
+
+func (ds *Datastore) resolveFieldName(fieldName string) (string, error) {
+	return ds.IndexDefinition.elasticFieldName(ds.typeName, fieldName)
+}
+
+// elasticQuery translates the query into an elastic.Query. Depending on the QueryContext the clauses are must or filter clauses
+func (q Query) elasticQuery(resolve fieldNameResolver) (elastic.Query, error) {
+	if q.kind == `` {
+		return elastic.NewMatchAllQuery(), nil
+	}
+	eq, err := q.clause(resolve)
+	if err != nil {
+		return nil, err
+	}
+	if q.kind == queryKindAnd || q.kind == queryKindOr || q.kind == queryKindNot {
+		return eq, nil
+	}
+	return q.boolQuery([]elastic.Query{eq}), nil
+}
+
+func (q Query) clause(resolve fieldNameResolver) (elastic.Query, error) {
+	switch q.kind {
+	case queryKindAnd, queryKindOr, queryKindNot:
+		children := make([]elastic.Query, 0, len(q.children))
+		musts, filters := []elastic.Query{}, []elastic.Query{}
+		for _, child := range q.children {
+			if child.kind == `` && q.kind == queryKindAnd {
+				continue
+			}
+			if !child.contextSet {
+				child.queryContext = q.queryContext
+			}
+			eq, err := child.clause(resolve)
+			if err != nil {
+				return nil, err
+			}
+			if child.queryContext {
+				musts = append(musts, eq)
+			} else {
+				filters = append(filters, eq)
+				if q.queryContext && q.kind == queryKindOr {
+					// a filter doesn't contribute to the score of the scored alternatives
+					eq = elastic.NewBoolQuery().Filter(eq)
+				}
+			}
+			children = append(children, eq)
+		}
+		switch q.kind {
+		case queryKindOr:
+			return elastic.NewBoolQuery().Should(children...).MinimumNumberShouldMatch(1), nil
+		case queryKindNot:
+			return elastic.NewBoolQuery().MustNot(children...), nil
+		}
+		return elastic.NewBoolQuery().Must(musts...).Filter(filters...), nil
+	case ``:
+		return elastic.NewMatchAllQuery(), nil
+	}
+
+	fieldName, err := resolve(q.field)
+	if err != nil {
+		return nil, err
+	}
+	switch q.kind {
+	case queryKindTerm:
+		return elastic.NewTermQuery(fieldName, q.value), nil
+	case queryKindTerms:
+		return elastic.NewTermsQuery(fieldName, q.values...), nil
+	case queryKindMatch:
+		return elastic.NewMatchQuery(fieldName, q.value), nil
+	case queryKindRange:
+		rq := elastic.NewRangeQuery(fieldName)
+		if q.gte != nil {
+			rq = rq.Gte(q.gte)
+		}
+		if q.lte != nil {
+			rq = rq.Lte(q.lte)
+		}
+		if q.gt != nil {
+			rq = rq.Gt(q.gt)
+		}
+		if q.lt != nil {
+			rq = rq.Lt(q.lt)
+		}
+		return rq, nil
+	case queryKindExists:
+		return elastic.NewExistsQuery(fieldName), nil
+	case queryKindPrefix:
+		return elastic.NewPrefixQuery(fieldName, q.value.(string)), nil
+	}
+	return nil, errors.Errorf("unknown query kind %s", q.kind)
+}
+
+func (q Query) boolQuery(clauses []elastic.Query) *elastic.BoolQuery {
+	if q.queryContext {
+		return elastic.NewBoolQuery().Must(clauses...)
+	}
+	return elastic.NewBoolQuery().Filter(clauses...)
 }
@@ -0,0 +1,217 @@
+package elasticorm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// UnknownFieldError is returned by ParseQuery, when the query string refers to a field, which isn't mapped
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %s", e.Field)
+}
+
+// ParseQuery parses a query string, as users type it into a search box, into a Query. The query string consists of
+// whitespace separated terms, which must all match:
+//
+//	status:open            the field has the value - a full text match for text fields
+//	author:"Jane Doe"      values with whitespace are quoted
+//	created>2023-01-01     a range - >, >=, < and <= are supported
+//	-status:closed         a leading minus negates the term
+//	gopher                 terms without a field are matched against the fields tagged with elasticorm:"searchable"
+//
+// The field names are struct field names or their JSON names. Fields, which aren't mapped, are rejected with an
+// UnknownFieldError - the query string is never passed to elasticsearch as it is
+func (ds *Datastore) ParseQuery(queryString string) (Query, error) {
+	tokens, err := tokenizeQueryString(queryString)
+	if err != nil {
+		return Query{}, err
+	}
+	clauses := []Query{}
+	freeText := []string{}
+	for _, token := range tokens {
+		negated := len(token) > 1 && token[0] == '-'
+		if negated {
+			token = token[1:]
+		}
+		clause, text, err := ds.parseQueryStringTerm(token)
+		if err != nil {
+			return Query{}, err
+		}
+		if text != `` && !negated {
+			freeText = append(freeText, text)
+			continue
+		}
+		if text != `` {
+			clause, err = ds.freeTextQuery(text)
+			if err != nil {
+				return Query{}, err
+			}
+		}
+		if negated {
+			clause = Not(clause)
+		}
+		clauses = append(clauses, clause)
+	}
+	if len(freeText) > 0 {
+		clause, err := ds.freeTextQuery(strings.Join(freeText, ` `))
+		if err != nil {
+			return Query{}, err
+		}
+		clauses = append(clauses, clause)
+	}
+	if len(clauses) == 0 {
+		return Query{}, nil
+	}
+	return And(clauses...).WithQueryContext(true), nil
+}
+
+// tokenizeQueryString splits the query string at whitespace outside of quotes
+func tokenizeQueryString(queryString string) ([]string, error) {
+	tokens := []string{}
+	current := []rune{}
+	quoted, escaped := false, false
+	for _, r := range queryString {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && unicode.IsSpace(r):
+			if len(current) > 0 {
+				tokens = append(tokens, string(current))
+				current = current[:0]
+			}
+			continue
+		}
+		current = append(current, r)
+	}
+	if quoted {
+		return nil, errors.Wrap(ErrInvalidQueryString, `unterminated quote`)
+	}
+	if len(current) > 0 {
+		tokens = append(tokens, string(current))
+	}
+	return tokens, nil
+}
+
+// parseQueryStringTerm parses a single term of a query string. For a term without a field name the text is returned instead of a query
+func (ds *Datastore) parseQueryStringTerm(token string) (Query, string, error) {
+	pos, operator := queryStringOperator(token)
+	if operator == `` {
+		text, err := unquoteQueryStringValue(token)
+		return Query{}, text, err
+	}
+	field := token[:pos]
+	if field == `` || strings.ContainsAny(field, `"\`) {
+		return Query{}, ``, errors.Wrapf(ErrInvalidQueryString, `invalid field name in %s`, token)
+	}
+	value, err := unquoteQueryStringValue(token[pos+len(operator):])
+	if err != nil {
+		return Query{}, ``, err
+	}
+	if value == `` {
+		return Query{}, ``, errors.Wrapf(ErrInvalidQueryString, `missing value in %s`, token)
+	}
+	fieldName, cfg, err := ds.queryStringField(field)
+	if err != nil {
+		return Query{}, ``, err
+	}
+	switch operator {
+	case `>`:
+		return Query{kind: queryKindRange, field: fieldName, gt: value}, ``, nil
+	case `>=`:
+		return Range(fieldName, value, nil), ``, nil
+	case `<`:
+		return Query{kind: queryKindRange, field: fieldName, lt: value}, ``, nil
+	case `<=`:
+		return Range(fieldName, nil, value), ``, nil
+	}
+	if cfg.Type == `text` {
+		return Match(fieldName, value), ``, nil
+	}
+	return Term(fieldName, value), ``, nil
+}
+
+// queryStringOperator returns the position and the operator of the first operator outside of quotes
+func queryStringOperator(token string) (int, string) {
+	quoted := false
+	for pos := 0; pos < len(token); pos++ {
+		switch c := token[pos]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ':':
+			return pos, `:`
+		case c == '>' || c == '<':
+			if pos+1 < len(token) && token[pos+1] == '=' {
+				return pos, token[pos : pos+2]
+			}
+			return pos, token[pos : pos+1]
+		}
+	}
+	return -1, ``
+}
+
+// unquoteQueryStringValue removes the quotes around a value and unescapes it
+func unquoteQueryStringValue(value string) (string, error) {
+	if !strings.HasPrefix(value, `"`) {
+		if strings.Contains(value, `"`) {
+			return ``, errors.Wrapf(ErrInvalidQueryString, `unexpected quote in %s`, value)
+		}
+		return value, nil
+	}
+	if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+		return ``, errors.Wrapf(ErrInvalidQueryString, `unexpected quote in %s`, value)
+	}
+	unquoted := []rune{}
+	escaped := false
+	for _, r := range value[1 : len(value)-1] {
+		if !escaped && r == '\\' {
+			escaped = true
+			continue
+		}
+		if !escaped && r == '"' {
+			return ``, errors.Wrapf(ErrInvalidQueryString, `unexpected quote in %s`, value)
+		}
+		escaped = false
+		unquoted = append(unquoted, r)
+	}
+	return string(unquoted), nil
+}
+
+// queryStringField returns the struct field name and the mapping of a field name in a query string, which may be a struct field name or a JSON name
+func (ds *Datastore) queryStringField(name string) (string, MappingFieldConfig, error) {
+	if _, cfg, err := ds.IndexDefinition.elasticField(ds.typeName, name); err == nil {
+		return name, cfg, nil
+	}
+	fieldName, err := ds.IndexDefinition.structFieldName(ds.typeName, name)
+	if err != nil {
+		return ``, MappingFieldConfig{}, &UnknownFieldError{Field: name}
+	}
+	_, cfg, err := ds.IndexDefinition.elasticField(ds.typeName, fieldName)
+	if err != nil {
+		return ``, MappingFieldConfig{}, &UnknownFieldError{Field: name}
+	}
+	return fieldName, cfg, nil
+}
+
+// freeTextQuery matches the text against the fields tagged with elasticorm:"searchable"
+func (ds *Datastore) freeTextQuery(text string) (Query, error) {
+	if len(ds.searchableFields) == 0 {
+		return Query{}, errors.Wrapf(ErrInvalidQueryString, `%s has no field name and there are no searchable fields`, text)
+	}
+	matches := make([]Query, len(ds.searchableFields))
+	for n, f := range ds.searchableFields {
+		matches[n] = Match(f.name, text)
+	}
+	return Or(matches...), nil
+}
@@ -0,0 +1,80 @@
+package elasticorm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestParseQuery(t *testing.T) {
+	type Ticket struct {
+		ID      string    `json:"id" elasticorm:"id"`
+		Title   string    `json:"title" elasticorm:"searchable,sortable"`
+		Status  string    `json:"status" elasticorm:"type=keyword"`
+		Author  string    `json:"author" elasticorm:"type=keyword"`
+		Created time.Time `json:"created"`
+	}
+
+	_, ds := initDatastore(t, &Ticket{})
+	tickets := []Ticket{
+		{Title: `Crash on startup`, Status: `open`, Author: `Jane Doe`, Created: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: `Slow search`, Status: `open`, Author: `John Doe`, Created: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: `Crash on shutdown`, Status: `closed`, Author: `Jane Doe`, Created: time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, ticket := range tickets {
+		err := ds.Create(&ticket)
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: ``, expected: []string{`Crash on shutdown`, `Crash on startup`, `Slow search`}},
+		{query: `status:open`, expected: []string{`Crash on startup`, `Slow search`}},
+		{query: `status:open author:"Jane Doe"`, expected: []string{`Crash on startup`}},
+		{query: `Status:open created>2023-02-01`, expected: []string{`Crash on startup`}},
+		{query: `created>=2023-02-01`, expected: []string{`Crash on startup`, `Slow search`}},
+		{query: `created<2023-01-01`, expected: []string{`Crash on shutdown`}},
+		{query: `-status:closed`, expected: []string{`Crash on startup`, `Slow search`}},
+		{query: `crash -status:open`, expected: []string{`Crash on shutdown`}},
+		{query: `title:search`, expected: []string{`Slow search`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ds.ParseQuery(tt.query)
+			ok(t, err)
+			found := []Ticket{}
+			err = ds.Search(q, &found, ds.SetSorting(`Title`, `asc`))
+			ok(t, err)
+			titles := []string{}
+			for _, ticket := range found {
+				titles = append(titles, ticket.Title)
+			}
+			equals(t, tt.expected, titles)
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	type Ticket struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Status string `json:"status" elasticorm:"type=keyword"`
+	}
+
+	ds, err := elasticorm.NewDatastore(nil, elasticorm.ForStruct(&Ticket{}))
+	ok(t, err)
+
+	_, err = ds.ParseQuery(`status:open assignee:me`)
+	unknown, isUnknown := errors.Cause(err).(*elasticorm.UnknownFieldError)
+	assert(t, isUnknown, `expected an UnknownFieldError, got %v`, err)
+	equals(t, `assignee`, unknown.Field)
+
+	for _, query := range []string{`author:"Jane Doe`, `:open`, `status:`, `status:op"en`, `created>`} {
+		_, err = ds.ParseQuery(query)
+		equals(t, elasticorm.ErrInvalidQueryString, errors.Cause(err))
+	}
+}
@@ -0,0 +1,129 @@
+package elasticorm_test
+
+import (
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+)
+
+func TestQueryString(t *testing.T) {
+	tests := []struct {
+		title        string
+		query        elasticorm.Query
+		expectedJSON string
+	}{
+		{
+			title:        `Empty query`,
+			query:        elasticorm.Query{},
+			expectedJSON: `{"match_all":{}}`,
+		},
+		{
+			title:        `Term query in filter context`,
+			query:        elasticorm.Term(`Email`, `foo@bar.com`),
+			expectedJSON: `{"bool":{"filter":{"term":{"Email":"foo@bar.com"}}}}`,
+		},
+		{
+			title:        `Term query in query context`,
+			query:        elasticorm.Term(`Email`, `foo@bar.com`).WithQueryContext(true),
+			expectedJSON: `{"bool":{"must":{"term":{"Email":"foo@bar.com"}}}}`,
+		},
+		{
+			title: `Combined query`,
+			query: elasticorm.And(
+				elasticorm.Exists(`Email`),
+				elasticorm.Not(elasticorm.Term(`Gender`, `male`)),
+			),
+			expectedJSON: `{"bool":{"filter":[{"exists":{"field":"Email"}},{"bool":{"must_not":{"term":{"Gender":"male"}}}}]}}`,
+		},
+		{
+			title: `Combined query keeping the context of a child`,
+			query: elasticorm.And(
+				elasticorm.Match(`Bio`, `coffee`).WithQueryContext(true),
+				elasticorm.Term(`Gender`, `female`),
+			),
+			expectedJSON: `{"bool":{"filter":{"term":{"Gender":"female"}},"must":{"match":{"Bio":{"query":"coffee"}}}}}`,
+		},
+		{
+			title: `Combined query in query context with a filter child`,
+			query: elasticorm.Or(
+				elasticorm.Match(`Bio`, `coffee`),
+				elasticorm.Term(`Gender`, `female`).WithQueryContext(false),
+			).WithQueryContext(true),
+			expectedJSON: `{"bool":{"minimum_should_match":"1","should":[{"match":{"Bio":{"query":"coffee"}}},{"bool":{"filter":{"term":{"Gender":"female"}}}}]}}`,
+		},
+		{
+			title:        `Combined query with an empty query`,
+			query:        elasticorm.And(elasticorm.Query{}, elasticorm.Exists(`Email`)),
+			expectedJSON: `{"bool":{"filter":{"exists":{"field":"Email"}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			equals(t, tt.expectedJSON, tt.query.String())
+		})
+	}
+}
+
+func TestDatastoreSearch(t *testing.T) {
+	type User struct {
+		ID     string `json:"id" elasticorm:"id"`
+		Name   string `json:"name" elasticorm:"sortable"`
+		Gender string `json:"gender" elasticorm:"type=keyword"`
+		Age    int    `json:"age"`
+	}
+	_, ds := initDatastore(t, &User{})
+
+	err := ds.Create(&User{Name: `Unknown No. 1`, Gender: `female`, Age: 20})
+	ok(t, err)
+	err = ds.Create(&User{Name: `Unknown No. 2`, Gender: `male`, Age: 30})
+	ok(t, err)
+	err = ds.Create(&User{Name: `Unknown No. 3`, Gender: `female`, Age: 40})
+	ok(t, err)
+	ds.Refresh()
+
+	tests := []struct {
+		title      string
+		query      elasticorm.Query
+		foundNames []string
+	}{
+		{
+			title:      `All users`,
+			query:      elasticorm.Query{},
+			foundNames: []string{`Unknown No. 1`, `Unknown No. 2`, `Unknown No. 3`},
+		},
+		{
+			title:      `Female users`,
+			query:      elasticorm.Term(`Gender`, `female`),
+			foundNames: []string{`Unknown No. 1`, `Unknown No. 3`},
+		},
+		{
+			title: `Female users or users older than 25`,
+			query: elasticorm.Or(
+				elasticorm.Term(`Gender`, `female`),
+				elasticorm.Range(`Age`, 25, nil),
+			),
+			foundNames: []string{`Unknown No. 1`, `Unknown No. 2`, `Unknown No. 3`},
+		},
+		{
+			title: `Female users older than 25`,
+			query: elasticorm.And(
+				elasticorm.Terms(`Gender`, `female`, `diverse`),
+				elasticorm.Not(elasticorm.Range(`Age`, nil, 25)),
+			),
+			foundNames: []string{`Unknown No. 3`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			found := []User{}
+			err := ds.Search(tt.query, &found, ds.SetSorting(`Name`, `asc`))
+			ok(t, err)
+			equals(t, len(tt.foundNames), len(found))
+			for n, name := range tt.foundNames {
+				equals(t, name, found[n].Name)
+			}
+		})
+	}
+}
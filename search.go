@@ -0,0 +1,138 @@
+package elasticorm
+
+import (
+	"sync"
+
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// searchQuery collects the clauses QueryOptFuncs add to the query of one search
+type searchQuery struct {
+	filters []elastic.Query // clauses in filter context
+	musts   []elastic.Query // clauses in query context
+	offset  int
+	limit   int
+	sorted  bool // wether a sorting has been set
+	cursor  bool // wether the search uses search_after cursors
+	after   bool // wether the search continues after a cursor
+	peek    bool // wether one hit more than the limit is fetched, to tell if there is a next page
+
+	fullText  *elastic.MultiMatchQuery // the query of a full text search, which the full text options configure
+	facetSize int                      // the maximum number of values per facet of a faceted search
+}
+
+// combine returns the query of a find method combined with the clauses of the QueryOptFuncs
+func (sq *searchQuery) combine(query elastic.Query) elastic.Query {
+	if len(sq.filters) == 0 && len(sq.musts) == 0 {
+		return query
+	}
+	return elastic.NewBoolQuery().
+		Must(append([]elastic.Query{query}, sq.musts...)...).
+		Filter(sq.filters...)
+}
+
+// searchRegistry keeps track of the searches, which are currently built, so that QueryOptFuncs can add clauses to them
+type searchRegistry struct {
+	mutex    sync.Mutex
+	searches map[*elastic.SearchService]*searchQuery
+}
+
+func (r *searchRegistry) register(srv *elastic.SearchService) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.searches == nil {
+		r.searches = make(map[*elastic.SearchService]*searchQuery)
+	}
+	r.searches[srv] = &searchQuery{limit: defaultLimit}
+}
+
+func (r *searchRegistry) unregister(srv *elastic.SearchService) *searchQuery {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	sq := r.searches[srv]
+	delete(r.searches, srv)
+	return sq
+}
+
+// update calls fn with the searchQuery of the search and reports wether the search is registered
+func (r *searchRegistry) update(srv *elastic.SearchService, fn func(*searchQuery)) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	sq, ok := r.searches[srv]
+	if ok {
+		fn(sq)
+	}
+	return ok
+}
+
+func (ds *Datastore) search() *elastic.SearchService {
+	return ds.elasticClient.Search().
+		Index(ds.indexName).
+		Type(ds.typeName).
+		Version(ds.versionFieldName != `` || ds.metaFieldNames[metaVersion] != ``)
+}
+
+// defaultLimit is the number of hits elasticsearch returns, when no size is given
+const defaultLimit = 10
+
+// executeSearch applies the QueryOptFuncs on a new search for the query and executes it.
+// Besides the result it returns what the QueryOptFuncs added to the search
+func (ds *Datastore) executeSearch(query elastic.Query, opts []QueryOptFunc) (*elastic.SearchResult, *searchQuery, error) {
+	srv := ds.search()
+	ds.searches.register(srv)
+	for _, opt := range opts {
+		err := opt(srv)
+		if err != nil {
+			ds.searches.unregister(srv)
+			return nil, nil, err
+		}
+	}
+	sq := ds.searches.unregister(srv)
+	if sq.after && sq.offset != 0 {
+		// elasticsearch only accepts search_after with from=0
+		return nil, nil, errors.Wrap(ErrInvalidOption, `an offset can't be combined with a cursor`)
+	}
+	if sq.cursor {
+		addTieBreakerSort(srv, sq)
+	}
+	if sq.peek {
+		srv.Size(sq.limit + 1)
+	}
+	res, err := srv.Query(sq.combine(query)).Do(ds.Ctx)
+	return res, sq, err
+}
+
+// addFilter adds the filter to the search. It fails for searches, which haven't been built by the datastore,
+// e.g. when the option has been created by another datastore
+func (ds *Datastore) addFilter(srv *elastic.SearchService, filter elastic.Query) error {
+	ok := ds.searches.update(srv, func(sq *searchQuery) {
+		sq.filters = append(sq.filters, filter)
+	})
+	if !ok {
+		return errors.Wrap(ErrInvalidOption, `filter option used with a search of another datastore`)
+	}
+	return nil
+}
+
+// addMust adds the clause in query context to the search. It fails for searches, which haven't been built by the datastore
+func (ds *Datastore) addMust(srv *elastic.SearchService, must elastic.Query) error {
+	ok := ds.searches.update(srv, func(sq *searchQuery) {
+		sq.musts = append(sq.musts, must)
+	})
+	if !ok {
+		return errors.Wrap(ErrInvalidOption, `match option used with a search of another datastore`)
+	}
+	return nil
+}
+
+// MatchField adds a full text match on the field to the search. Unlike the filters it contributes to the score of the results
+func (ds *Datastore) MatchField(fieldName string, text interface{}) QueryOptFunc {
+	return func(srv *elastic.SearchService) error {
+		elasticFieldName, err := ds.IndexDefinition.elasticFieldName(ds.typeName, fieldName)
+		if err != nil {
+			return err
+		}
+		return ds.addMust(srv, elastic.NewMatchQuery(elasticFieldName, text))
+	}
+}
@@ -0,0 +1,58 @@
+package elasticorm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestSetSortingByMappedType(t *testing.T) {
+	type Product struct {
+		ID       string    `json:"id" elasticorm:"id"`
+		Name     string    `json:"name" elasticorm:"fields=sort:keyword;en:text/english"`
+		SKU      string    `json:"sku" elasticorm:"keyword"`
+		Price    float64   `json:"price"`
+		Released time.Time `json:"released"`
+		Details  string    `json:"details"`
+	}
+
+	_, ds := initDatastore(t, &Product{})
+	products := []Product{
+		{Name: `Bicycle`, SKU: `B-2`, Price: 499.0, Released: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: `Anvil`, SKU: `C-1`, Price: 99.5, Released: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: `Canoe`, SKU: `A-3`, Price: 1200.0, Released: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, p := range products {
+		err := ds.Create(&p)
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	tests := []struct {
+		field    string
+		expected []string
+	}{
+		{field: `Name`, expected: []string{`Anvil`, `Bicycle`, `Canoe`}},
+		{field: `SKU`, expected: []string{`Canoe`, `Bicycle`, `Anvil`}},
+		{field: `Price`, expected: []string{`Anvil`, `Bicycle`, `Canoe`}},
+		{field: `Released`, expected: []string{`Bicycle`, `Canoe`, `Anvil`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			found := []Product{}
+			err := ds.FindAll(&found, ds.SetSorting(tt.field, `asc`))
+			ok(t, err)
+			names := []string{}
+			for _, p := range found {
+				names = append(names, p.Name)
+			}
+			equals(t, tt.expected, names)
+		})
+	}
+
+	found := []Product{}
+	err := ds.FindAll(&found, ds.SetSorting(`Details`, `asc`))
+	equals(t, elasticorm.ErrNotSortable, errors.Cause(err))
+}
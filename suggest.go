@@ -0,0 +1,76 @@
+package elasticorm
+
+import (
+	"strings"
+
+	"github.com/olivere/elastic"
+	"github.com/pkg/errors"
+)
+
+// suggestionName is the name of the suggester Suggest sends
+const suggestionName = `suggestion`
+
+// suggestContextsForOption returns the category contexts of the suggest option. The value is a semicolon separated
+// list of the elasticsearch field names, which are used as context - e.g. elasticorm:"suggest=genre;language"
+func suggestContextsForOption(value string) []MappingContextConfig {
+	names := strings.Split(value, `;`)
+	contexts := make([]MappingContextConfig, 0, len(names))
+	for _, name := range names {
+		if name == `` {
+			continue
+		}
+		contexts = append(contexts, MappingContextConfig{Name: name, Type: `category`, Path: name})
+	}
+	return contexts
+}
+
+// SuggestContext restricts the suggestions to documents with one of the values in the context
+type SuggestContext struct {
+	Name   string
+	Values []string
+}
+
+// Suggest decodes up to size documents, which have a value in the field starting with text, into results.
+// The field must be tagged with elasticorm:"suggest"
+func (ds *Datastore) Suggest(fieldName string, text string, size int, results interface{}, contexts ...SuggestContext) error {
+	elasticFieldName, cfg, err := ds.IndexDefinition.elasticField(ds.typeName, fieldName)
+	if err != nil {
+		return err
+	}
+	if cfg.Type != `completion` {
+		return errors.Wrapf(ErrNoSuggestField, `suggest on %s`, fieldName)
+	}
+	suggester := elastic.NewCompletionSuggester(suggestionName).
+		Field(elasticFieldName).
+		Text(text).
+		Size(size)
+	for _, c := range contexts {
+		suggester = suggester.ContextQueries(elastic.NewSuggesterCategoryQuery(c.Name, c.Values...))
+	}
+	res, err := ds.search().
+		Size(0).
+		Suggester(suggester).
+		Do(ds.Ctx)
+	if err != nil {
+		return err
+	}
+	return ds.DecodeElasticResponses(suggestionsToResults(res.Suggest[suggestionName]), results)
+}
+
+func suggestionsToResults(suggestions []elastic.SearchSuggestion) []QueryResult {
+	res := []QueryResult{}
+	for _, suggestion := range suggestions {
+		for _, option := range suggestion.Options {
+			score := option.Score
+			res = append(res, queryResult{
+				id:     option.Id,
+				source: option.Source,
+				meta: Metadata{
+					Index: option.Index,
+					Score: &score,
+				},
+			})
+		}
+	}
+	return res
+}
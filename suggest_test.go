@@ -0,0 +1,56 @@
+package elasticorm_test
+
+import (
+	"testing"
+
+	"github.com/fvosberg/elasticorm"
+	"github.com/pkg/errors"
+)
+
+func TestSuggest(t *testing.T) {
+	type Movie struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Title string `json:"title" elasticorm:"suggest"`
+		Genre string `json:"genre" elasticorm:"type=keyword"`
+	}
+
+	_, ds := initDatastore(t, &Movie{})
+	for _, m := range []Movie{{Title: `Star Wars`, Genre: `scifi`}, {Title: `Star Trek`, Genre: `scifi`}, {Title: `Casablanca`, Genre: `drama`}} {
+		err := ds.Create(&m)
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	found := []Movie{}
+	err := ds.Suggest(`Title`, `sta`, 5, &found)
+	ok(t, err)
+	equals(t, 2, len(found))
+	for _, m := range found {
+		assert(t, m.ID != ``, `expected the ID of the suggested movie to be set`)
+		equals(t, `scifi`, m.Genre)
+	}
+
+	err = ds.Suggest(`Genre`, `sci`, 5, &found)
+	equals(t, elasticorm.ErrNoSuggestField, errors.Cause(err))
+}
+
+func TestSuggestWithContext(t *testing.T) {
+	type Movie struct {
+		ID    string `json:"id" elasticorm:"id"`
+		Title string `json:"title" elasticorm:"suggest=genre"`
+		Genre string `json:"genre" elasticorm:"type=keyword"`
+	}
+
+	_, ds := initDatastore(t, &Movie{})
+	for _, m := range []Movie{{Title: `Star Wars`, Genre: `scifi`}, {Title: `Stand By Me`, Genre: `drama`}} {
+		err := ds.Create(&m)
+		ok(t, err)
+	}
+	ds.Refresh()
+
+	found := []Movie{}
+	err := ds.Suggest(`Title`, `sta`, 5, &found, elasticorm.SuggestContext{Name: `genre`, Values: []string{`drama`}})
+	ok(t, err)
+	equals(t, 1, len(found))
+	equals(t, `Stand By Me`, found[0].Title)
+}